@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"website/internal/config"
+	"website/internal/content"
+	"website/internal/database"
+	"website/internal/export"
+	"website/internal/handlers"
+	"website/internal/posts"
+	"website/internal/transfer"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/storage"
+)
+
+// runCommand runs a maintenance command given on the command line, such as "migrate up", instead of the server
+func runCommand(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(ctx, args[1:])
+	case "copy":
+		return copyCommand(ctx, args[1:])
+	case "export":
+		return exportCommand(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q (available: migrate, copy, export)", args[0])
+	}
+}
+
+// migrateCommand applies, reverts or lists the PostgreSQL schema migrations, or migrates stored posts:
+//
+//	migrate [up]           apply every pending migration
+//	migrate down [steps]   revert the newest applied migrations, one by default
+//	migrate status         list migrations and when they were applied
+//	migrate content        move post content to per-post keys and hashed blobs, in either storage mode
+//	migrate slugs          give posts stored before slugs existed a slug, so their numeric URLs redirect
+func migrateCommand(ctx context.Context, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	steps := 1
+	if action == "down" && len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of steps %q", args[1])
+		}
+		steps = n
+	}
+
+	switch action {
+	case "content":
+		return migratePosts(ctx, handlers.Env.MigrateContent)
+	case "slugs":
+		return migratePosts(ctx, func(env handlers.Env, ctx context.Context) error {
+			assigned, err := env.BackfillSlugs(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("assigned slugs to %d post(s)\n", assigned)
+			return nil
+		})
+	case "up", "down", "status":
+	default:
+		return fmt.Errorf("unknown migrate action %q (available: up, down, status, content, slugs)", action)
+	}
+
+	url, err := config.DatabaseURL()
+	if err != nil {
+		return err
+	}
+
+	pool, err := database.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	return database.WithMigrator(ctx, pool, func(m *database.Migrator) error {
+		switch action {
+		case "down":
+			reverted, err := m.Down(ctx, steps)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("reverted %d migration(s)\n", reverted)
+		case "status":
+			statuses, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+			for _, status := range statuses {
+				applied := "pending"
+				if status.Applied {
+					applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+			}
+			return w.Flush()
+		default:
+			applied, err := m.Up(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("applied %d migration(s)\n", applied)
+		}
+		return nil
+	})
+}
+
+// Helper function to run a migration of stored posts against the backends the server is configured with
+func migratePosts(ctx context.Context, migrate func(handlers.Env, context.Context) error) error {
+	conf, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	env, err := newEnv(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	return migrate(env, ctx)
+}
+
+// copyCommand copies every post and its content from one storage backend to another, then checks the copy:
+//
+//	copy -from local -to gcs [-dry-run] [-overwrite]
+//
+// Both backends are configured from the usual environment variables, DB_* and POSTS_DIRECTORY for local and
+// PROJECT_ID, GCS_BUCKET_NAME and GCS_PREFIX for gcs.
+func copyCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("copy", flag.ContinueOnError)
+	from := flags.String("from", "", "backend to copy from: local or gcs")
+	to := flags.String("to", "", "backend to copy to: local or gcs")
+	dryRun := flags.Bool("dry-run", false, "report what would be copied without writing anything")
+	overwrite := flags.Bool("overwrite", false, "replace destination posts that differ from the source")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return errors.New("copy needs both -from and -to")
+	}
+	if *from == *to {
+		return fmt.Errorf("cannot copy the %s backend onto itself", *from)
+	}
+
+	source, closeSource, err := openBackend(ctx, *from)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	destination, closeDestination, err := openBackend(ctx, *to)
+	if err != nil {
+		return err
+	}
+	defer closeDestination()
+
+	report, err := transfer.Copy(ctx, source, destination, transfer.Options{DryRun: *dryRun, Overwrite: *overwrite})
+	printReport(report)
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		verified, err := transfer.Verify(ctx, source, destination)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+		fmt.Println("verification:")
+		printReport(verified)
+		if !verified.OK() {
+			return errors.New("the destination does not match the source")
+		}
+	}
+
+	if !report.OK() {
+		return errors.New("some posts were not copied")
+	}
+	return nil
+}
+
+// exportCommand renders the public site into a directory of static files, with the server's configuration:
+//
+//	export [-out dist] [-static static]
+func exportCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "dist", "directory to write the site to")
+	static := flags.String("static", "static", "directory of static assets to copy alongside the pages")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	env, err := newEnv(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	routes, err := env.ExportRoutes(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := export.Export(ctx, publicRoutes(env), routes, export.Options{OutDir: *out, StaticDir: *static, BaseURL: conf.SiteURL})
+	if err != nil {
+		return err
+	}
+
+	for _, failure := range result.Failed {
+		fmt.Println("failed:", failure)
+	}
+	fmt.Printf("exported %d pages, %d redirects, %d files and %d static files to %s\n",
+		result.Pages, result.Redirects, result.Files, result.Static, *out)
+
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d routes could not be exported", len(result.Failed))
+	}
+	return nil
+}
+
+// Helper function to print the posts a copy or verification did something with, followed by a summary
+func printReport(report transfer.Report) {
+	prefix := ""
+	if report.DryRun {
+		prefix = "[dry run] "
+	}
+
+	for _, result := range report.Results {
+		if result.Action == transfer.ActionUnchanged {
+			continue
+		}
+
+		line := fmt.Sprintf("%spost %d %q: %s", prefix, result.ID, result.Title, result.Action)
+		if result.Assets > 0 {
+			line += fmt.Sprintf(" (%d assets)", result.Assets)
+		}
+		fmt.Println(line)
+		for _, detail := range result.Details {
+			fmt.Println("    " + detail)
+		}
+	}
+
+	counts := make([]string, 0, 5)
+	for _, action := range []transfer.Action{transfer.ActionCreated, transfer.ActionUpdated, transfer.ActionUnchanged, transfer.ActionConflict, transfer.ActionFailed} {
+		counts = append(counts, fmt.Sprintf("%d %s", report.Count(action), action))
+	}
+	fmt.Printf("%s%d posts: %s\n", prefix, len(report.Results), strings.Join(counts, ", "))
+}
+
+// Helper function to connect to a storage backend by its STORAGE_MODE name, without the caching and rendering
+// decorators the server adds, so content is read and written as stored. The returned function closes the clients.
+func openBackend(ctx context.Context, mode string) (transfer.Backend, func(), error) {
+	databaseTimeouts, storageTimeouts, err := config.Timeouts()
+	if err != nil {
+		return transfer.Backend{}, nil, err
+	}
+
+	switch mode {
+	case "local":
+		url, err := config.DatabaseURL()
+		if err != nil {
+			return transfer.Backend{}, nil, err
+		}
+
+		pool, err := database.Connect(ctx, url)
+		if err != nil {
+			return transfer.Backend{}, nil, err
+		}
+
+		// The destination may be a database the server has never started against
+		if _, err := database.Migrate(ctx, pool); err != nil {
+			pool.Close()
+			return transfer.Backend{}, nil, err
+		}
+
+		directory := os.Getenv("POSTS_DIRECTORY")
+		if directory == "" {
+			directory = "posts"
+		}
+
+		backend := transfer.Backend{
+			Posts:   posts.New(pool, databaseTimeouts),
+			Content: content.NewFilesystemService(directory),
+		}
+		return backend, pool.Close, nil
+	case "gcs":
+		projectID := os.Getenv("PROJECT_ID")
+		if projectID == "" {
+			return transfer.Backend{}, nil, errors.New("missing environment variable PROJECT_ID (required for gcs storage mode)")
+		}
+
+		bucket := os.Getenv("GCS_BUCKET_NAME")
+		if bucket == "" {
+			return transfer.Backend{}, nil, errors.New("missing environment variable GCS_BUCKET_NAME (required for gcs storage mode)")
+		}
+
+		firestoreClient, err := firestore.NewClient(ctx, projectID)
+		if err != nil {
+			return transfer.Backend{}, nil, err
+		}
+
+		gcsClient, err := storage.NewClient(ctx)
+		if err != nil {
+			firestoreClient.Close()
+			return transfer.Backend{}, nil, err
+		}
+
+		backend := transfer.Backend{
+			Posts:   posts.NewFirestoreRepository(firestoreClient, databaseTimeouts),
+			Content: content.NewGCSService(gcsClient, bucket, os.Getenv("GCS_PREFIX"), storageTimeouts),
+		}
+		return backend, func() {
+			firestoreClient.Close()
+			gcsClient.Close()
+		}, nil
+	default:
+		return transfer.Backend{}, nil, fmt.Errorf("unknown backend %q (available: local, gcs)", mode)
+	}
+}
@@ -1,17 +1,5 @@
+-- Resets the database to an empty schema. The tables are created by the migrations in
+-- internal/database/migrations, which the server applies at startup and `website migrate up` applies on demand.
 DROP SCHEMA IF EXISTS public CASCADE;
 
 CREATE SCHEMA IF NOT EXISTS public;
-
-CREATE TABLE public.posts (
-    id serial primary key,
-    title varchar(80),
-    author varchar(80) default 'Adam Shkolnik',
-    created timestamp default CURRENT_TIMESTAMP,
-    edited timestamp default  CURRENT_TIMESTAMP,
-    body varchar(80),
-    description varchar(500)
-);
-
--- INSERT INTO public.posts VALUES
--- (DEFAULT, 'POST A', DEFAULT, DEFAULT, DEFAULT, 'a.html', 'Short Description for Post A'),
--- (DEFAULT, 'POST B', DEFAULT, DEFAULT, DEFAULT, 'b.html', 'Short Description for Post B');
\ No newline at end of file
@@ -0,0 +1,187 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"website/internal/content"
+	"website/internal/markdown"
+)
+
+// MaxSize is the largest total uncompressed size of a bundle
+const MaxSize = 50 << 20
+
+// MaxFiles is the largest number of entries a bundle may contain
+const MaxFiles = 200
+
+// ErrNoDocument is returned when a bundle has no single HTML or Markdown file to use as the post
+var ErrNoDocument = errors.New("bundle must contain exactly one HTML or Markdown file")
+
+// File is a file extracted from a bundle. Path is where it was in the archive relative to the post document,
+// and Name is the cleaned asset name it is stored under.
+type File struct {
+	Path string
+	Name string
+	Data []byte
+}
+
+// Bundle is a post document along with the assets it links to
+type Bundle struct {
+	Document File
+	Assets   []File
+}
+
+// IsBundle reports whether an uploaded filename is a zip bundle
+func IsBundle(filename string) bool {
+	return strings.EqualFold(path.Ext(filename), ".zip")
+}
+
+// Read extracts and validates a zip bundle. Entries must stay inside the archive, and assets must sit
+// next to or below the post document so their links resolve inside the post's namespace.
+func Read(data []byte) (Bundle, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Bundle{}, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	if len(archive.File) > MaxFiles {
+		return Bundle{}, fmt.Errorf("bundle has more than %d files", MaxFiles)
+	}
+
+	var documents, files []File
+	var total int64
+	for _, entry := range archive.File {
+		name := entry.Name
+
+		if strings.Contains(name, `\`) || !fs.ValidPath(strings.TrimSuffix(name, "/")) {
+			return Bundle{}, fmt.Errorf("invalid path in bundle: %s", name)
+		}
+		if entry.Mode()&fs.ModeSymlink != 0 {
+			return Bundle{}, fmt.Errorf("symbolic links are not allowed in bundles: %s", name)
+		}
+
+		// Skip directories and the metadata archivers add, such as __MACOSX/ and .DS_Store
+		if entry.FileInfo().IsDir() || isJunk(name) {
+			continue
+		}
+
+		// Sizes in the archive headers can't be trusted, so count what is actually decompressed
+		file, err := readEntry(entry)
+		if err != nil {
+			return Bundle{}, err
+		}
+		total += int64(len(file.Data))
+		if total > MaxSize {
+			return Bundle{}, fmt.Errorf("bundle is larger than %d MB uncompressed", MaxSize>>20)
+		}
+
+		if isDocument(name) {
+			documents = append(documents, file)
+		} else {
+			files = append(files, file)
+		}
+	}
+
+	document, others, err := chooseDocument(documents)
+	if err != nil {
+		return Bundle{}, err
+	}
+	files = append(files, others...)
+
+	bundle := Bundle{Document: document}
+	dir := path.Dir(document.Path)
+	names := make(map[string]bool)
+
+	for _, file := range files {
+		rel := file.Path
+		if dir != "." {
+			if !strings.HasPrefix(rel, dir+"/") {
+				return Bundle{}, fmt.Errorf("file %s is outside the directory of %s", file.Path, document.Path)
+			}
+			rel = strings.TrimPrefix(rel, dir+"/")
+		}
+
+		name := cleanPath(rel)
+		if err := content.ValidateAssetName(name); err != nil {
+			return Bundle{}, fmt.Errorf("unsupported file in bundle: %s", file.Path)
+		}
+		if names[name] {
+			return Bundle{}, fmt.Errorf("duplicate file in bundle: %s", file.Path)
+		}
+		names[name] = true
+
+		bundle.Assets = append(bundle.Assets, File{Path: rel, Name: name, Data: file.Data})
+	}
+
+	bundle.Document.Name = path.Base(document.Path)
+	return bundle, nil
+}
+
+func readEntry(entry *zip.File) (File, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return File{}, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, content.MaxAssetSize+1))
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	if len(data) > content.MaxAssetSize {
+		return File{}, fmt.Errorf("file too large: %s", entry.Name)
+	}
+
+	return File{Path: entry.Name, Data: data}, nil
+}
+
+// chooseDocument picks the post document, preferring an index file when the bundle has several,
+// and returns the remaining documents so they are validated like any other file
+func chooseDocument(documents []File) (File, []File, error) {
+	if len(documents) == 1 {
+		return documents[0], nil, nil
+	}
+
+	index := -1
+	for i, document := range documents {
+		if strings.HasPrefix(strings.ToLower(path.Base(document.Path)), "index.") {
+			if index >= 0 {
+				return File{}, nil, ErrNoDocument
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return File{}, nil, ErrNoDocument
+	}
+
+	others := append(append([]File{}, documents[:index]...), documents[index+1:]...)
+	return documents[index], others, nil
+}
+
+func isDocument(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".html") || markdown.IsMarkdown(name)
+}
+
+func isJunk(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") || segment == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanPath cleans every segment of a relative path the way uploaded asset filenames are cleaned
+func cleanPath(rel string) string {
+	segments := strings.Split(rel, "/")
+	for i, segment := range segments {
+		segments[i] = content.CleanAssetFilename(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("extracts the document and assets", func(t *testing.T) {
+		bundle, err := Read(buildZip(t, map[string]string{
+			"export/index.html":        "<p>Hello</p>",
+			"export/images/my pic.png": "png",
+			"export/.DS_Store":         "junk",
+			"__MACOSX/export/._x.png":  "junk",
+		}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bundle.Document.Name != "index.html" || string(bundle.Document.Data) != "<p>Hello</p>" {
+			t.Errorf("unexpected document %+v", bundle.Document)
+		}
+		if len(bundle.Assets) != 1 {
+			t.Fatalf("expected 1 asset, got %d", len(bundle.Assets))
+		}
+		if asset := bundle.Assets[0]; asset.Path != "images/my pic.png" || asset.Name != "images/my-pic.png" {
+			t.Errorf("unexpected asset %+v", asset)
+		}
+	})
+
+	t.Run("uses a lone Markdown file as the document", func(t *testing.T) {
+		bundle, err := Read(buildZip(t, map[string]string{"post.md": "# Post", "image.png": "png"}))
+		if err != nil || bundle.Document.Name != "post.md" {
+			t.Errorf("expected post.md as the document, got %+v (%v)", bundle.Document, err)
+		}
+	})
+
+	rejected := map[string]map[string]string{
+		"no document":             {"image.png": "png"},
+		"several documents":       {"a.html": "a", "b.html": "b"},
+		"documents besides index": {"index.md": "# Post", "notes.md": "# Notes"},
+		"path traversal":          {"index.html": "x", "../evil.png": "png"},
+		"absolute path":           {"index.html": "x", "/etc/evil.png": "png"},
+		"backslash path":          {"index.html": "x", `..\evil.png`: "png"},
+		"outside document dir":    {"post/index.html": "x", "other/image.png": "png"},
+		"unsupported file type":   {"index.html": "x", "run.exe": "exe"},
+		"duplicate clean names":   {"index.html": "x", "a b.png": "1", "a-b.png": "2"},
+		"oversized file":          {"index.html": "x", "big.png": strings.Repeat("0", 20<<20+1)},
+	}
+
+	for name, files := range rejected {
+		t.Run("rejects "+name, func(t *testing.T) {
+			if _, err := Read(buildZip(t, files)); err == nil {
+				t.Errorf("expected bundle to be rejected")
+			}
+		})
+	}
+
+	t.Run("rejects invalid archives", func(t *testing.T) {
+		if _, err := Read([]byte("not a zip")); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestRewriteLinks(t *testing.T) {
+	bundle := Bundle{Assets: []File{
+		{Path: "images/my pic.png", Name: "images/my-pic.png"},
+		{Path: "diagram.svg", Name: "diagram.svg"},
+	}}
+
+	t.Run("html", func(t *testing.T) {
+		input := `<img src="./images/my%20pic.png" alt="x"><a href="diagram.svg#top">d</a><a href="https://example.com/diagram.svg">e</a><img src="missing.png"><img srcset="diagram.svg 1x, other.png 2x">`
+		expected := `<img src="/media/12/images/my-pic.png" alt="x"><a href="/media/12/diagram.svg#top">d</a><a href="https://example.com/diagram.svg">e</a><img src="missing.png"><img srcset="/media/12/diagram.svg 1x, other.png 2x">`
+
+		if got := bundle.RewriteLinks(input, false, "/media/12/"); got != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, got)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		input := "![Pic](images/my%20pic.png \"Title\") and [chart](<diagram.svg>)\n\n[ref]: diagram.svg\n\n[site](https://example.com)\n"
+		expected := "![Pic](/media/12/images/my-pic.png \"Title\") and [chart](</media/12/diagram.svg>)\n\n[ref]: /media/12/diagram.svg\n\n[site](https://example.com)\n"
+
+		if got := bundle.RewriteLinks(input, true, "/media/12/"); got != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, got)
+		}
+	})
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range files {
+		// Headers keep names exactly as given, including unsafe ones
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,151 @@
+package bundle
+
+import (
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// linkAttributes are the HTML attributes that can reference a bundled file
+var linkAttributes = map[string]bool{"src": true, "href": true, "poster": true, "srcset": true}
+
+// Markdown inline link and image destinations, and link reference definitions
+var (
+	markdownInline    = regexp.MustCompile(`(\]\(\s*)(<[^>\n]*>|[^\s)]+)`)
+	markdownReference = regexp.MustCompile(`(?m)^( {0,3}\[[^\]\n]+\]:[ \t]*)(<[^>\n]*>|\S+)`)
+)
+
+// RewriteLinks points relative links in the post document at the bundle's stored assets.
+// baseURL is the URL the assets are served under, such as "/media/12/".
+// Links to anything that is not in the bundle are left as they are.
+func (b Bundle) RewriteLinks(document string, isMarkdown bool, baseURL string) string {
+	assets := make(map[string]string, len(b.Assets))
+	for _, asset := range b.Assets {
+		assets[asset.Path] = asset.Name
+	}
+
+	resolve := func(ref string) string {
+		return resolveLink(ref, assets, baseURL)
+	}
+
+	if isMarkdown {
+		rewrite := func(re *regexp.Regexp) {
+			document = re.ReplaceAllStringFunc(document, func(match string) string {
+				parts := re.FindStringSubmatch(match)
+				destination := parts[2]
+				if strings.HasPrefix(destination, "<") {
+					return parts[1] + "<" + resolve(strings.Trim(destination, "<>")) + ">"
+				}
+				return parts[1] + resolve(destination)
+			})
+		}
+		rewrite(markdownInline)
+		rewrite(markdownReference)
+	}
+
+	// Markdown can embed raw HTML, so its tags are rewritten too
+	return rewriteHTMLLinks(document, resolve)
+}
+
+func rewriteHTMLLinks(document string, resolve func(string) string) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(document))
+
+	var b strings.Builder
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			return b.String()
+		}
+
+		// Everything not rewritten is copied byte for byte
+		raw := string(tokenizer.Raw())
+		if tokenType != nethtml.StartTagToken && tokenType != nethtml.SelfClosingTagToken {
+			b.WriteString(raw)
+			continue
+		}
+
+		token := tokenizer.Token()
+		changed := false
+		for i, attr := range token.Attr {
+			if !linkAttributes[attr.Key] {
+				continue
+			}
+
+			var value string
+			if attr.Key == "srcset" {
+				value = rewriteSrcset(attr.Val, resolve)
+			} else {
+				value = resolve(attr.Val)
+			}
+
+			if value != attr.Val {
+				token.Attr[i].Val = value
+				changed = true
+			}
+		}
+
+		if !changed {
+			b.WriteString(raw)
+			continue
+		}
+
+		b.WriteString("<" + token.Data)
+		for _, attr := range token.Attr {
+			b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+		}
+		if tokenType == nethtml.SelfClosingTagToken {
+			b.WriteString(" /")
+		}
+		b.WriteString(">")
+	}
+}
+
+func rewriteSrcset(srcset string, resolve func(string) string) string {
+	candidates := strings.Split(srcset, ",")
+	changed := false
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		if resolved := resolve(fields[0]); resolved != fields[0] {
+			fields[0] = resolved
+			changed = true
+		}
+		candidates[i] = strings.Join(fields, " ")
+	}
+
+	// Keep the author's formatting unless a candidate actually changed
+	if !changed {
+		return srcset
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// resolveLink maps a relative link onto the URL of the bundled file it refers to,
+// keeping its query and fragment, or returns the link unchanged
+func resolveLink(ref string, assets map[string]string, baseURL string) string {
+	parsed, err := url.Parse(ref)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" || strings.HasPrefix(parsed.Path, "/") {
+		return ref
+	}
+
+	name, ok := assets[path.Clean(parsed.Path)]
+	if !ok {
+		return ref
+	}
+
+	suffix := ""
+	if parsed.RawQuery != "" {
+		suffix += "?" + parsed.RawQuery
+	}
+	if parsed.Fragment != "" {
+		suffix += "#" + parsed.EscapedFragment()
+	}
+
+	return baseURL + name + suffix
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats reports how well a cache is doing
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Size      int64  `json:"size"`
+	MaxSize   int64  `json:"maxSize"`
+}
+
+// HitRate is the share of lookups that were served from the cache, 0 before the first lookup
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type entry[V any] struct {
+	key     string
+	value   V
+	size    int64
+	expires time.Time
+}
+
+// LRU is a cache bounded by the total size of its values that evicts the least recently used entries first.
+// Entries also expire after a fixed time to live, so values changed elsewhere are eventually reloaded.
+// It is safe for concurrent use.
+type LRU[V any] struct {
+	mu      sync.Mutex
+	maxSize int64
+	ttl     time.Duration
+	sizeOf  func(V) int64
+	size    int64
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	now func() time.Time
+}
+
+// New creates a cache holding values up to a total of maxSize as measured by sizeOf, each for at most ttl.
+// A ttl of 0 keeps entries until they are evicted or deleted.
+func New[V any](maxSize int64, ttl time.Duration, sizeOf func(V) int64) *LRU[V] {
+	return &LRU[V]{
+		maxSize: maxSize,
+		ttl:     ttl,
+		sizeOf:  sizeOf,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached value for key, reporting false when there is none or it has expired
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry[V])
+		if e.expires.IsZero() || c.now().Before(e.expires) {
+			c.order.MoveToFront(element)
+			c.hits.Add(1)
+			return e.value, true
+		}
+		c.remove(element)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set caches value under key, evicting the least recently used entries to make room.
+// Values larger than the whole cache are not cached.
+func (c *LRU[V]) Set(key string, value V) {
+	size := c.sizeOf(value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	if size > c.maxSize {
+		return
+	}
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[V]{key: key, value: value, size: size, expires: expires})
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes key from the cache
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Clear removes every entry from the cache, keeping its stats
+func (c *LRU[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.size = 0
+}
+
+// Stats returns the cache's hit and miss counts along with its current size
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   len(c.entries),
+		Size:      c.size,
+		MaxSize:   c.maxSize,
+	}
+}
+
+// Helper function to unlink an entry; the caller holds the lock
+func (c *LRU[V]) remove(element *list.Element) {
+	e := c.order.Remove(element).(*entry[V])
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
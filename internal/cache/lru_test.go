@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func length(s string) int64 { return int64(len(s)) }
+
+func TestLRU_GetSet(t *testing.T) {
+	c := New(100, 0, length)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a miss on an empty cache")
+	}
+
+	c.Set("a", "hello")
+	if got, ok := c.Get("a"); !ok || got != "hello" {
+		t.Errorf("expected hello, got %q (ok %v)", got, ok)
+	}
+
+	c.Set("a", "replaced")
+	if got, _ := c.Get("a"); got != "replaced" {
+		t.Errorf("expected replaced value, got %q", got)
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a miss after delete")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 0 || stats.Size != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.HitRate() != 0.5 {
+		t.Errorf("expected hit rate 0.5, got %v", stats.HitRate())
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(10, 0, length)
+
+	c.Set("a", "aaaa")
+	c.Set("b", "bbbb")
+	c.Get("a") // a is now more recently used than b
+	c.Set("c", "cccc")
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to be kept")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("expected c to be kept")
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 || stats.Size != 8 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Values that can never fit are not cached at all
+	c.Set("big", "0123456789ab")
+	if _, ok := c.Get("big"); ok {
+		t.Error("expected an oversized value not to be cached")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected an oversized value not to evict others")
+	}
+}
+
+func TestLRU_Expiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(100, time.Minute, length)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", "hello")
+
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a hit before the entry expires")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a miss once the entry expired")
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Size != 0 {
+		t.Errorf("expected the expired entry to be removed, got %+v", stats)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	"website/internal/timeout"
 )
 
 type Config struct {
@@ -18,6 +22,74 @@ type Config struct {
 	GCSBucketName     string
 	GCSPrefix         string
 	TurnstileSecret   string
+	SiteURL           string        // public base URL used for absolute links, e.g. in feeds
+	FeedFullContent   bool          // include full post content in feeds rather than just descriptions
+	RobotsDisallow    []string      // paths robots.txt asks crawlers to skip in addition to /admin/
+	TrashRetention    time.Duration // how long deleted posts stay in the trash before being purged, 0 keeps them
+
+	SanitizeOnRender        bool     // sanitize untrusted HTML posts again when rendering, not only on upload
+	SanitizeAllowElements   []string // elements the HTML sanitizer allows in addition to its defaults
+	SanitizeAllowAttributes []string // attributes allowed in addition to the defaults, as "element:attribute" or "attribute"
+
+	ContentCacheSize int64         // bytes of post content to keep in memory, 0 turns the content cache off
+	ContentCacheTTL  time.Duration // how long cached content is served before it is read again
+
+	PostsCacheSize int           // posts and post lists to keep in memory, 0 turns the posts cache off
+	PostsCacheTTL  time.Duration // how long cached posts are served before they are read again
+
+	DatabaseTimeouts timeout.Timeouts // how long a single read or write of posts may take, 0 leaves it unbounded
+	StorageTimeouts  timeout.Timeouts // how long a single read or write of post content or assets may take
+}
+
+// DefaultTrashRetentionDays is how many days deleted posts are kept when TRASH_RETENTION_DAYS is not set
+const DefaultTrashRetentionDays = 30
+
+// Content cache defaults used when CONTENT_CACHE_SIZE_MB and CONTENT_CACHE_TTL are not set
+const (
+	DefaultContentCacheSizeMB = 64
+	DefaultContentCacheTTL    = 5 * time.Minute
+)
+
+// Posts cache defaults used when POSTS_CACHE_SIZE and POSTS_CACHE_TTL are not set.
+// The TTL is short because it also decides how late scheduled posts show up.
+const (
+	DefaultPostsCacheSize = 1000
+	DefaultPostsCacheTTL  = time.Minute
+)
+
+// Timeout defaults used when the DB_*_TIMEOUT and STORAGE_*_TIMEOUT variables are not set.
+// Storage writes get longest since assets can be up to content.MaxAssetSize.
+const (
+	DefaultDatabaseReadTimeout  = 5 * time.Second
+	DefaultDatabaseWriteTimeout = 10 * time.Second
+	DefaultStorageReadTimeout   = 10 * time.Second
+	DefaultStorageWriteTimeout  = time.Minute
+)
+
+// DatabaseURL builds the PostgreSQL connection string from the DB_* variables. It is separate from GetConfig
+// so commands that only need the database, such as migrate, don't require the server's other settings.
+func DatabaseURL() (string, error) {
+	host := os.Getenv("DB_HOST")
+	if host == "" {
+		return "", errors.New("missing environment variable DB_HOST (required for local storage mode)")
+	}
+
+	user := os.Getenv("DB_USER")
+	if user == "" {
+		return "", errors.New("missing environment variable DB_USER (required for local storage mode)")
+	}
+
+	password := os.Getenv("DB_PASSWORD")
+	if password == "" {
+		return "", errors.New("missing environment variable DB_PASSWORD (required for local storage mode)")
+	}
+
+	name := os.Getenv("DB_NAME")
+	if name == "" {
+		return "", errors.New("missing environment variable DB_NAME (required for local storage mode)")
+	}
+
+	return fmt.Sprintf("user=%s password=%s dbname=%s host=%s sslmode=disable", user, password, name, host), nil
 }
 
 func GetConfig() (Config, error) {
@@ -37,27 +109,11 @@ func GetConfig() (Config, error) {
 
 	// Database configuration (only required for local/PostgreSQL mode)
 	if config.StorageMode == "local" {
-		host := os.Getenv("DB_HOST")
-		if host == "" {
-			return config, errors.New("missing environment variable DB_HOST (required for local storage mode)")
-		}
-
-		user := os.Getenv("DB_USER")
-		if user == "" {
-			return config, errors.New("missing environment variable DB_USER (required for local storage mode)")
-		}
-
-		password := os.Getenv("DB_PASSWORD")
-		if password == "" {
-			return config, errors.New("missing environment variable DB_PASSWORD (required for local storage mode)")
+		url, err := DatabaseURL()
+		if err != nil {
+			return config, err
 		}
-
-		name := os.Getenv("DB_NAME")
-		if name == "" {
-			return config, errors.New("missing environment variable DB_NAME (required for local storage mode)")
-		}
-
-		config.URL = fmt.Sprintf("user=%s password=%s dbname=%s host=%s sslmode=disable", user, password, name, host)
+		config.URL = url
 	}
 
 	config.EmailKey = os.Getenv("EMAIL_KEY")
@@ -96,7 +152,116 @@ func GetConfig() (Config, error) {
 		return config, errors.New("missing environment variable TURNSTILE_SECRET")
 	}
 
+	// Feed configuration; without SITE_URL absolute links are built from the request host, and responses
+	// carrying them are only cached privately
+	config.SiteURL = strings.TrimSuffix(os.Getenv("SITE_URL"), "/")
+	config.FeedFullContent = os.Getenv("FEED_FULL_CONTENT") == "true"
+
+	// Extra robots.txt exclusions, e.g. "/" to keep a dev environment out of search engines
+	config.RobotsDisallow = splitList(os.Getenv("ROBOTS_DISALLOW"))
+
+	// Trash retention in days; 0 turns automatic purging off
+	retentionDays := DefaultTrashRetentionDays
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return config, fmt.Errorf("invalid TRASH_RETENTION_DAYS %q: must be a whole number of days", value)
+		}
+		retentionDays = days
+	}
+	config.TrashRetention = time.Duration(retentionDays) * 24 * time.Hour
+
+	// HTML sanitizer policy; Markdown posts are always sanitized when rendered since they are stored as source
+	config.SanitizeOnRender = os.Getenv("SANITIZE_ON_RENDER") == "true"
+	config.SanitizeAllowElements = splitList(os.Getenv("SANITIZE_ALLOW_ELEMENTS"))
+	config.SanitizeAllowAttributes = splitList(os.Getenv("SANITIZE_ALLOW_ATTRIBUTES"))
+
+	// Content cache; the TTL bounds how long posts saved by another instance can be served stale
+	cacheSizeMB := DefaultContentCacheSizeMB
+	if value := os.Getenv("CONTENT_CACHE_SIZE_MB"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return config, fmt.Errorf("invalid CONTENT_CACHE_SIZE_MB %q: must be a whole number of megabytes", value)
+		}
+		cacheSizeMB = size
+	}
+	config.ContentCacheSize = int64(cacheSizeMB) << 20
+
+	config.ContentCacheTTL = DefaultContentCacheTTL
+	if value := os.Getenv("CONTENT_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return config, fmt.Errorf("invalid CONTENT_CACHE_TTL %q: must be a duration such as 5m", value)
+		}
+		config.ContentCacheTTL = ttl
+	}
+
+	// Posts cache; writes through this instance clear it straight away
+	config.PostsCacheSize = DefaultPostsCacheSize
+	if value := os.Getenv("POSTS_CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return config, fmt.Errorf("invalid POSTS_CACHE_SIZE %q: must be a whole number of entries", value)
+		}
+		config.PostsCacheSize = size
+	}
+
+	config.PostsCacheTTL = DefaultPostsCacheTTL
+	if value := os.Getenv("POSTS_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return config, fmt.Errorf("invalid POSTS_CACHE_TTL %q: must be a duration such as 1m", value)
+		}
+		config.PostsCacheTTL = ttl
+	}
+
+	// Per-operation timeouts, each also bounded by the request or job the operation runs for
+	databaseTimeouts, storageTimeouts, err := Timeouts()
+	if err != nil {
+		return config, err
+	}
+	config.DatabaseTimeouts = databaseTimeouts
+	config.StorageTimeouts = storageTimeouts
+
 	log.Println(config.URL)
 
 	return config, nil
 }
+
+// Timeouts reads the per-operation database and storage timeouts from the DB_*_TIMEOUT and STORAGE_*_TIMEOUT
+// variables, falling back to the defaults for any that are not set
+func Timeouts() (database, storage timeout.Timeouts, err error) {
+	timeouts := []struct {
+		name     string
+		fallback time.Duration
+		target   *time.Duration
+	}{
+		{"DB_READ_TIMEOUT", DefaultDatabaseReadTimeout, &database.Read},
+		{"DB_WRITE_TIMEOUT", DefaultDatabaseWriteTimeout, &database.Write},
+		{"STORAGE_READ_TIMEOUT", DefaultStorageReadTimeout, &storage.Read},
+		{"STORAGE_WRITE_TIMEOUT", DefaultStorageWriteTimeout, &storage.Write},
+	}
+	for _, t := range timeouts {
+		*t.target = t.fallback
+		if value := os.Getenv(t.name); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return database, storage, fmt.Errorf("invalid %s %q: must be a duration such as 10s", t.name, value)
+			}
+			*t.target = d
+		}
+	}
+
+	return database, storage, nil
+}
+
+// Helper function to split a comma-separated environment variable, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
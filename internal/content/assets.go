@@ -0,0 +1,102 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// AssetsDirectory is where backends keep uploaded images and attachments, relative to their post storage
+const AssetsDirectory = "assets"
+
+// ErrAssetNotFound is returned when no asset is stored under the requested name
+var ErrAssetNotFound = errors.New("asset not found")
+
+// MaxAssetSize is the largest asset that can be uploaded
+const MaxAssetSize = 20 << 20
+
+// Asset is an uploaded image or attachment. Data is left empty in listings.
+type Asset struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+	Data     []byte
+}
+
+// assetTypes maps the extensions accepted for uploads to the content type they are served with
+var assetTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".avif": "image/avif",
+	".svg":  "image/svg+xml",
+	".ico":  "image/x-icon",
+	".pdf":  "application/pdf",
+	".txt":  "text/plain; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".json": "application/json",
+	".zip":  "application/zip",
+	".mp3":  "audio/mpeg",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+}
+
+// AssetContentType returns the content type an asset is served with, or an empty string for extensions that can't be uploaded
+func AssetContentType(name string) string {
+	return assetTypes[strings.ToLower(path.Ext(name))]
+}
+
+// IsImage reports whether an asset is an image
+func IsImage(name string) bool {
+	mediaType, _, _ := mime.ParseMediaType(AssetContentType(name))
+	return strings.HasPrefix(mediaType, "image/")
+}
+
+// ValidateAssetName checks that a slash-separated asset name such as "12/diagram.png"
+// stays inside the assets directory and has an extension that can be uploaded
+func ValidateAssetName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) {
+		return fmt.Errorf("invalid asset name: %s", name)
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		// Hidden files and empty or relative segments are never part of an asset name
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return fmt.Errorf("invalid asset name: %s", name)
+		}
+	}
+
+	if AssetContentType(name) == "" {
+		return fmt.Errorf("unsupported asset type: %s", name)
+	}
+
+	return nil
+}
+
+// CleanAssetFilename turns an uploaded filename into a safe asset filename, keeping letters, digits, dots, dashes and underscores
+func CleanAssetFilename(filename string) string {
+	// Browsers on Windows may send the full client path
+	filename = path.Base(strings.ReplaceAll(filename, `\`, "/"))
+
+	var b strings.Builder
+	for _, r := range filename {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	return strings.TrimLeft(b.String(), ".-")
+}
+
+// PostAssetPrefix is the namespace a post's assets are stored under
+func PostAssetPrefix(postID int) string {
+	return fmt.Sprintf("%d/", postID)
+}
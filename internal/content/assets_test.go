@@ -0,0 +1,60 @@
+package content
+
+import "testing"
+
+func TestValidateAssetName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"12/diagram.png", true},
+		{"12/sub/photo.JPG", true},
+		{"notes.pdf", true},
+		{"", false},
+		{"/12/diagram.png", false},
+		{"12/../secret.png", false},
+		{"12//diagram.png", false},
+		{"12/.hidden.png", false},
+		{`12\diagram.png`, false},
+		{"12/script.exe", false},
+		{"12/noextension", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAssetName(tt.name)
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.name, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected %q to be rejected", tt.name)
+			}
+		})
+	}
+}
+
+func TestCleanAssetFilename(t *testing.T) {
+	tests := map[string]string{
+		"diagram.png":                 "diagram.png",
+		"My Photo (1).jpg":            "My-Photo--1-.jpg",
+		`C:\Users\me\Desktop\pic.gif`: "pic.gif",
+		"../../etc/passwd.txt":        "passwd.txt",
+		".hidden.png":                 "hidden.png",
+		"résumé.pdf":                  "r-sum-.pdf",
+	}
+
+	for input, expected := range tests {
+		if got := CleanAssetFilename(input); got != expected {
+			t.Errorf("CleanAssetFilename(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestAssetContentType(t *testing.T) {
+	if got := AssetContentType("12/Photo.JPG"); got != "image/jpeg" {
+		t.Errorf("expected image/jpeg, got %q", got)
+	}
+	if !IsImage("a.svg") || IsImage("a.pdf") {
+		t.Error("IsImage misclassified svg or pdf")
+	}
+}
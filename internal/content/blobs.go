@@ -0,0 +1,83 @@
+package content
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BlobsDirectory is where backends keep content blobs, named by the SHA-256 hash of their bytes, relative to their post storage
+const BlobsDirectory = "blobs"
+
+// BlobGracePeriod keeps recently written blobs from being garbage collected. A save writes its blob before
+// the reference to it, so a blob this young may belong to a save that has not finished yet.
+const BlobGracePeriod = time.Hour
+
+// ErrCorruptContent is returned when stored content no longer matches the hash it was saved under
+var ErrCorruptContent = errors.New("content failed integrity check")
+
+// A content file or revision holds a reference to its blob rather than the content itself, so identical
+// uploads share one blob and revisions cost a few bytes
+const refPrefix = "sha256:"
+
+// refSize is the length of a reference: the prefix, a hex SHA-256 hash and a newline
+const refSize = len(refPrefix) + sha256.Size*2 + 1
+
+// HashContent returns the hex SHA-256 hash content is stored under
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Helper function to get the name of a blob relative to the blobs directory, fanned out by the first
+// two characters of its hash to keep directory listings short
+func blobName(hash string) string {
+	return hash[:2] + "/" + hash
+}
+
+// Helper function to format the reference a content file holds to its blob
+func formatRef(hash string) []byte {
+	return []byte(refPrefix + hash + "\n")
+}
+
+// Helper function to read a reference, reporting false for content stored before blobs existed
+func parseRef(data []byte) (string, bool) {
+	if len(data) != refSize || !bytes.HasPrefix(data, []byte(refPrefix)) || data[refSize-1] != '\n' {
+		return "", false
+	}
+
+	hash := string(data[len(refPrefix) : refSize-1])
+	if !isHash(hash) {
+		return "", false
+	}
+
+	return hash, true
+}
+
+// Helper function to check that a name is a lowercase hex SHA-256 hash
+func isHash(name string) bool {
+	return len(name) == sha256.Size*2 && isHex(name)
+}
+
+// Helper function to check that a name is made of lowercase hex digits only
+func isHex(name string) bool {
+	for _, c := range name {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Helper function to check that a blob's bytes still hash to its name
+func verifyBlob(hash string, data []byte) error {
+	if actual := HashContent(data); actual != hash {
+		return fmt.Errorf("%w: blob %s hashes to %s", ErrCorruptContent, hash, actual)
+	}
+
+	return nil
+}
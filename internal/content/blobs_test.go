@@ -0,0 +1,174 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRef(t *testing.T) {
+	hash := HashContent([]byte("<p>Hello</p>"))
+
+	if got, ok := parseRef(formatRef(hash)); !ok || got != hash {
+		t.Errorf("expected reference to %s, got %q (ok %v)", hash, got, ok)
+	}
+
+	for _, data := range []string{"", "<p>Hello</p>", "sha256:" + hash, "sha256:" + hash[:63] + "X\n"} {
+		if _, ok := parseRef([]byte(data)); ok {
+			t.Errorf("expected %q not to be a reference", data)
+		}
+	}
+}
+
+func TestFilesystemService_Blobs(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewFilesystemService(dir)
+	ctx := context.Background()
+
+	const body = "<p>Same content</p>"
+	hash := HashContent([]byte(body))
+
+	t.Run("identical content is stored once", func(t *testing.T) {
+		if err := fs.SaveContent(ctx, "1/post.html", body); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := fs.SaveContent(ctx, "2/post.html", body); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		blobs, _ := filepath.Glob(filepath.Join(dir, BlobsDirectory, "*", "*"))
+		if len(blobs) != 1 {
+			t.Errorf("expected one blob, got %v", blobs)
+		}
+
+		for _, key := range []string{"1/post.html", "2/post.html"} {
+			got, err := fs.GetContent(ctx, key)
+			if err != nil || got != body {
+				t.Errorf("expected %q from %s, got %q (%v)", body, key, got, err)
+			}
+
+			if got, _ := fs.ContentHash(ctx, key); got != hash {
+				t.Errorf("expected %s to refer to %s, got %q", key, hash, got)
+			}
+		}
+	})
+
+	t.Run("content stored before hashing is read as it is", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "legacy.html"), []byte("<p>Old</p>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := fs.GetContent(ctx, "legacy.html")
+		if err != nil || got != "<p>Old</p>" {
+			t.Errorf("expected legacy content, got %q (%v)", got, err)
+		}
+
+		if got, _ := fs.ContentHash(ctx, "legacy.html"); got != "" {
+			t.Errorf("expected no hash for legacy content, got %q", got)
+		}
+	})
+
+	t.Run("corrupted content is reported", func(t *testing.T) {
+		if err := os.WriteFile(fs.blobPath(hash), []byte("<p>Tampered</p>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fs.GetContent(ctx, "1/post.html"); !errors.Is(err, ErrCorruptContent) {
+			t.Errorf("expected ErrCorruptContent, got %v", err)
+		}
+
+		// Saving the same content again repairs the blob
+		if err := fs.SaveContent(ctx, "1/post.html", body); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got, err := fs.GetContent(ctx, "2/post.html"); err != nil || got != body {
+			t.Errorf("expected repaired content, got %q (%v)", got, err)
+		}
+	})
+
+	t.Run("revisions refer to the same blob", func(t *testing.T) {
+		if err := fs.SaveRevision(ctx, "1/post.html", "1-1-post.html"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := fs.GetRevision(ctx, "1-1-post.html")
+		if err != nil || got != body {
+			t.Errorf("expected %q, got %q (%v)", body, got, err)
+		}
+	})
+
+	t.Run("garbage collection removes unreferenced blobs", func(t *testing.T) {
+		if err := fs.SaveContent(ctx, "3/post.html", "<p>Replaced</p>"); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.SaveContent(ctx, "3/post.html", "<p>Current</p>"); err != nil {
+			t.Fatal(err)
+		}
+
+		// Blobs inside the grace period are kept even when unreferenced
+		if removed, err := fs.CollectGarbage(ctx); err != nil || removed != 0 {
+			t.Errorf("expected nothing removed, got %d (%v)", removed, err)
+		}
+
+		old := time.Now().Add(-2 * BlobGracePeriod)
+		blobs, _ := filepath.Glob(filepath.Join(dir, BlobsDirectory, "*", "*"))
+		for _, blob := range blobs {
+			if err := os.Chtimes(blob, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		removed, err := fs.CollectGarbage(ctx)
+		if err != nil || removed != 1 {
+			t.Errorf("expected one blob removed, got %d (%v)", removed, err)
+		}
+
+		if _, err := os.Stat(fs.blobPath(HashContent([]byte("<p>Replaced</p>")))); !os.IsNotExist(err) {
+			t.Errorf("expected the replaced content's blob to be removed, got %v", err)
+		}
+
+		for _, key := range []string{"1/post.html", "3/post.html"} {
+			if _, err := fs.GetContent(ctx, key); err != nil {
+				t.Errorf("expected %s to still be readable, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("reusing an old blob keeps it from garbage collection", func(t *testing.T) {
+		const orphan = "<p>Orphan</p>"
+		if err := fs.SaveContent(ctx, "4/post.html", orphan); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.SaveContent(ctx, "4/post.html", "<p>Moved on</p>"); err != nil {
+			t.Fatal(err)
+		}
+
+		blob := fs.blobPath(HashContent([]byte(orphan)))
+		old := time.Now().Add(-2 * BlobGracePeriod)
+		if err := os.Chtimes(blob, old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		// A collection that listed references before this save must still find the blob recent
+		if err := fs.SaveContent(ctx, "5/post.html", orphan); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(blob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(info.ModTime()) > BlobGracePeriod {
+			t.Errorf("expected the reused blob's modification time to be refreshed, got %v", info.ModTime())
+		}
+
+		if _, err := fs.CollectGarbage(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got, err := fs.GetContent(ctx, "5/post.html"); err != nil || got != orphan {
+			t.Errorf("expected the reused blob to survive, got %q (%v)", got, err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package content
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"website/internal/cache"
+)
+
+// CacheService decorates a ContentService with an in-memory cache of post content, so repeated views of a
+// post don't read the file or GCS object again. The cache is bounded by the total size of the content it holds,
+// evicts the least recently used posts first and reloads entries after their time to live, which bounds how long
+// changes saved by another instance go unnoticed.
+type CacheService struct {
+	next  ContentService
+	cache *cache.LRU[string]
+
+	// generation changes on every write so a read that raced with one does not cache what it read
+	generation atomic.Uint64
+}
+
+// NewCacheService wraps an existing content service with a cache of up to maxSize bytes of content,
+// each kept for at most ttl
+func NewCacheService(next ContentService, maxSize int64, ttl time.Duration) *CacheService {
+	return &CacheService{
+		next:  next,
+		cache: cache.New(maxSize, ttl, func(content string) int64 { return int64(len(content)) }),
+	}
+}
+
+// Stats returns the hit and miss counts of the content cache
+func (cs *CacheService) Stats() cache.Stats {
+	return cs.cache.Stats()
+}
+
+// GetContent serves content from the cache, reading it from the wrapped service on a miss
+func (cs *CacheService) GetContent(ctx context.Context, filename string) (string, error) {
+	if content, ok := cs.cache.Get(filename); ok {
+		return content, nil
+	}
+
+	generation := cs.generation.Load()
+	content, err := cs.next.GetContent(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+
+	if cs.generation.Load() == generation {
+		cs.cache.Set(filename, content)
+	}
+
+	return content, nil
+}
+
+// SaveContent saves through to the wrapped service and drops the cached copy
+func (cs *CacheService) SaveContent(ctx context.Context, filename, content string) error {
+	defer cs.invalidate(filename)
+	return cs.next.SaveContent(ctx, filename, content)
+}
+
+// SaveRevision passes through to the wrapped service
+func (cs *CacheService) SaveRevision(ctx context.Context, filename, revision string) error {
+	return cs.next.SaveRevision(ctx, filename, revision)
+}
+
+// GetRevision passes through to the wrapped service
+func (cs *CacheService) GetRevision(ctx context.Context, revision string) (string, error) {
+	return cs.next.GetRevision(ctx, revision)
+}
+
+// CopyContent copies through to the wrapped service and drops the cached copy of the destination
+func (cs *CacheService) CopyContent(ctx context.Context, from, to string) error {
+	defer cs.invalidate(to)
+	return cs.next.CopyContent(ctx, from, to)
+}
+
+// ContentHash passes through to the wrapped service
+func (cs *CacheService) ContentHash(ctx context.Context, filename string) (string, error) {
+	return cs.next.ContentHash(ctx, filename)
+}
+
+// DeleteContent deletes through to the wrapped service and drops the cached copy
+func (cs *CacheService) DeleteContent(ctx context.Context, filename string) error {
+	defer cs.invalidate(filename)
+	return cs.next.DeleteContent(ctx, filename)
+}
+
+// DeleteRevision passes through to the wrapped service
+func (cs *CacheService) DeleteRevision(ctx context.Context, revision string) error {
+	return cs.next.DeleteRevision(ctx, revision)
+}
+
+// CollectGarbage passes through to the wrapped service
+func (cs *CacheService) CollectGarbage(ctx context.Context) (int, error) {
+	return cs.next.CollectGarbage(ctx)
+}
+
+// SaveAsset passes through to the wrapped service
+func (cs *CacheService) SaveAsset(ctx context.Context, name string, data []byte) error {
+	return cs.next.SaveAsset(ctx, name, data)
+}
+
+// GetAsset passes through to the wrapped service
+func (cs *CacheService) GetAsset(ctx context.Context, name string) (Asset, error) {
+	return cs.next.GetAsset(ctx, name)
+}
+
+// ListAssets passes through to the wrapped service
+func (cs *CacheService) ListAssets(ctx context.Context, prefix string) ([]Asset, error) {
+	return cs.next.ListAssets(ctx, prefix)
+}
+
+// DeleteAsset passes through to the wrapped service
+func (cs *CacheService) DeleteAsset(ctx context.Context, name string) error {
+	return cs.next.DeleteAsset(ctx, name)
+}
+
+// Helper function to drop a cached file once a write to it has finished, whether or not it succeeded
+func (cs *CacheService) invalidate(filename string) {
+	cs.generation.Add(1)
+	cs.cache.Delete(filename)
+}
@@ -0,0 +1,73 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingService is an in-memory ContentService that counts the reads reaching it
+type countingService struct {
+	ContentService
+	files map[string]string
+	reads int
+}
+
+func (s *countingService) GetContent(ctx context.Context, filename string) (string, error) {
+	s.reads++
+	content, ok := s.files[filename]
+	if !ok {
+		return "", errors.New("post content not found: " + filename)
+	}
+	return content, nil
+}
+
+func (s *countingService) SaveContent(ctx context.Context, filename, content string) error {
+	s.files[filename] = content
+	return nil
+}
+
+func (s *countingService) DeleteContent(ctx context.Context, filename string) error {
+	delete(s.files, filename)
+	return nil
+}
+
+func TestCacheService(t *testing.T) {
+	next := &countingService{files: map[string]string{"1/post.html": "<p>One</p>"}}
+	cs := NewCacheService(next, 1<<20, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		content, err := cs.GetContent(context.Background(), "1/post.html")
+		if err != nil || content != "<p>One</p>" {
+			t.Fatalf("expected cached content, got %q (%v)", content, err)
+		}
+	}
+	if next.reads != 1 {
+		t.Errorf("expected one read of the wrapped service, got %d", next.reads)
+	}
+
+	if err := cs.SaveContent(context.Background(), "1/post.html", "<p>Updated</p>"); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := cs.GetContent(context.Background(), "1/post.html"); content != "<p>Updated</p>" {
+		t.Errorf("expected the saved content after invalidation, got %q", content)
+	}
+
+	if err := cs.DeleteContent(context.Background(), "1/post.html"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.GetContent(context.Background(), "1/post.html"); err == nil {
+		t.Error("expected deleted content not to be served from the cache")
+	}
+
+	// Errors are not cached
+	if _, err := cs.GetContent(context.Background(), "1/post.html"); err == nil {
+		t.Error("expected the missing file to be looked up again")
+	}
+
+	stats := cs.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 {
+		t.Errorf("expected 2 hits and 4 misses, got %+v", stats)
+	}
+}
@@ -1,12 +1,16 @@
 package content
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
-// FilesystemService implements ContentService for local filesystem storage
+// FilesystemService implements ContentService for local filesystem storage.
+// Reads and writes of single files are short, so only the directory walks stop early when the context is cancelled.
 type FilesystemService struct {
 	postsDirectory string
 }
@@ -19,7 +23,7 @@ func NewFilesystemService(postsDirectory string) *FilesystemService {
 }
 
 // GetContent retrieves HTML content from the local filesystem
-func (fs *FilesystemService) GetContent(filename string) (string, error) {
+func (fs *FilesystemService) GetContent(ctx context.Context, filename string) (string, error) {
 	// Construct the full file path
 	filePath := filepath.Join(fs.postsDirectory, filename)
 	
@@ -48,11 +52,11 @@ func (fs *FilesystemService) GetContent(filename string) (string, error) {
 		return "", fmt.Errorf("failed to read post content: %w", err)
 	}
 	
-	return string(content), nil
+	return fs.resolve(filename, content)
 }
 
 // SaveContent saves HTML content to the local filesystem
-func (fs *FilesystemService) SaveContent(filename, content string) error {
+func (fs *FilesystemService) SaveContent(ctx context.Context, filename, content string) error {
 	// Construct the full file path
 	filePath := filepath.Join(fs.postsDirectory, filename)
 	
@@ -72,15 +76,435 @@ func (fs *FilesystemService) SaveContent(filename, content string) error {
 		return fmt.Errorf("file path outside posts directory: %s", filename)
 	}
 	
-	// Ensure the posts directory exists
-	if err := os.MkdirAll(fs.postsDirectory, 0755); err != nil {
+	// Keys must also stay clear of the revisions, assets and blobs directories
+	if err := ValidateContentKey(filename); err != nil {
+		return err
+	}
+	
+	// Store the content itself once, under its hash
+	hash, err := fs.writeBlob([]byte(content))
+	if err != nil {
+		return err
+	}
+	
+	// Ensure the posts directory, or the post's own directory inside it, exists
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return fmt.Errorf("failed to create posts directory: %w", err)
 	}
 	
-	// Write the content to the file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	// Write the reference to the content to the file
+	if err := os.WriteFile(filePath, formatRef(hash), 0644); err != nil {
 		return fmt.Errorf("failed to write post content: %w", err)
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// SaveRevision stores a reference to a post file's content in the revisions directory inside the posts directory
+func (fs *FilesystemService) SaveRevision(ctx context.Context, filename, revision string) error {
+	content, err := fs.GetContent(ctx, filename)
+	if err != nil {
+		return err
+	}
+
+	revisionPath, err := fs.revisionPath(revision)
+	if err != nil {
+		return err
+	}
+
+	// The content is usually stored already, in which case the revision only costs its reference
+	hash, err := fs.writeBlob([]byte(content))
+	if err != nil {
+		return err
+	}
+
+	// Ensure the revisions directory exists
+	if err := os.MkdirAll(filepath.Dir(revisionPath), 0755); err != nil {
+		return fmt.Errorf("failed to create revisions directory: %w", err)
+	}
+
+	if err := os.WriteFile(revisionPath, formatRef(hash), 0644); err != nil {
+		return fmt.Errorf("failed to write post revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevision retrieves a stored revision from the revisions directory
+func (fs *FilesystemService) GetRevision(ctx context.Context, revision string) (string, error) {
+	revisionPath, err := fs.revisionPath(revision)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(revisionPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("post revision not found: %s", revision)
+		}
+		return "", fmt.Errorf("failed to read post revision: %w", err)
+	}
+
+	return fs.resolve(revision, content)
+}
+
+// Helper function to get the path of a revision file, rejecting names that could leave the revisions directory
+func (fs *FilesystemService) revisionPath(revision string) (string, error) {
+	if revision == "" || strings.Contains(revision, "..") || strings.ContainsAny(revision, `/\`) {
+		return "", fmt.Errorf("invalid revision name: %s", revision)
+	}
+
+	return filepath.Join(fs.postsDirectory, RevisionsDirectory, revision), nil
+}
+
+// CopyContent copies a post file to another filename inside the posts directory
+func (fs *FilesystemService) CopyContent(ctx context.Context, from, to string) error {
+	if err := ValidateContentKey(to); err != nil {
+		return err
+	}
+
+	content, err := fs.GetContent(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	return fs.SaveContent(ctx, to, content)
+}
+
+// ContentHash reads the hash a post file refers to
+func (fs *FilesystemService) ContentHash(ctx context.Context, filename string) (string, error) {
+	if err := ValidateContentKey(filename); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(fs.postsDirectory, filepath.FromSlash(filename)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("post content not found: %s", filename)
+		}
+		return "", fmt.Errorf("failed to read post content: %w", err)
+	}
+
+	hash, _ := parseRef(data)
+	return hash, nil
+}
+
+// DeleteContent removes a post file from the local filesystem
+func (fs *FilesystemService) DeleteContent(ctx context.Context, filename string) error {
+	// Construct the full file path
+	filePath := filepath.Join(fs.postsDirectory, filename)
+
+	// Security check: ensure the file is within the posts directory
+	absPostsDir, err := filepath.Abs(fs.postsDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for posts directory: %w", err)
+	}
+
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for file: %w", err)
+	}
+
+	// The posts directory itself is never a post file
+	if absFilePath == absPostsDir || !filepath.HasPrefix(absFilePath, absPostsDir) {
+		return fmt.Errorf("file path outside posts directory: %s", filename)
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete post content: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRevision removes a stored revision from the revisions directory
+func (fs *FilesystemService) DeleteRevision(ctx context.Context, revision string) error {
+	revisionPath, err := fs.revisionPath(revision)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(revisionPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete post revision: %w", err)
+	}
+
+	return nil
+}
+
+// CollectGarbage walks the posts directory for references to blobs, then removes the blobs nothing refers to
+func (fs *FilesystemService) CollectGarbage(ctx context.Context) (int, error) {
+	root := fs.postsDirectory
+	blobsRoot := filepath.Join(root, BlobsDirectory)
+	assetsRoot := filepath.Join(root, AssetsDirectory)
+
+	referenced := map[string]bool{}
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Nothing has been stored yet
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path == blobsRoot || path == assetsRoot {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		// References all have the same size, so other files don't need to be read
+		if info.Size() != int64(refSize) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if hash, ok := parseRef(data); ok {
+			referenced[hash] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find blob references: %w", err)
+	}
+
+	cutoff := time.Now().Add(-BlobGracePeriod)
+	removed := 0
+	err = filepath.WalkDir(blobsRoot, func(path string, entry os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if os.IsNotExist(err) && path == blobsRoot {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || referenced[entry.Name()] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to remove unreferenced blobs: %w", err)
+	}
+
+	return removed, nil
+}
+
+// SaveAsset writes an asset into the assets directory inside the posts directory
+func (fs *FilesystemService) SaveAsset(ctx context.Context, name string, data []byte) error {
+	assetPath, err := fs.assetPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(assetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create assets directory: %w", err)
+	}
+
+	if err := os.WriteFile(assetPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+
+	return nil
+}
+
+// GetAsset reads an asset from the assets directory
+func (fs *FilesystemService) GetAsset(ctx context.Context, name string) (Asset, error) {
+	assetPath, err := fs.assetPath(name)
+	if err != nil {
+		return Asset{}, err
+	}
+
+	data, err := os.ReadFile(assetPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Asset{}, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+		}
+		return Asset{}, fmt.Errorf("failed to read asset: %w", err)
+	}
+
+	info, err := os.Stat(assetPath)
+	if err != nil {
+		return Asset{}, fmt.Errorf("failed to stat asset: %w", err)
+	}
+
+	return Asset{Name: name, Size: info.Size(), Modified: info.ModTime(), Data: data}, nil
+}
+
+// ListAssets walks the assets directory for files whose names start with prefix
+func (fs *FilesystemService) ListAssets(ctx context.Context, prefix string) ([]Asset, error) {
+	root := filepath.Join(fs.postsDirectory, AssetsDirectory)
+
+	var assets []Asset
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// No assets have been uploaded yet
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		assets = append(assets, Asset{Name: name, Size: info.Size(), Modified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	// WalkDir visits files in lexical order, so the list is already sorted by name
+	return assets, nil
+}
+
+// DeleteAsset removes an asset from the assets directory
+func (fs *FilesystemService) DeleteAsset(ctx context.Context, name string) error {
+	assetPath, err := fs.assetPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(assetPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	return nil
+}
+
+// Helper function to get the path of an asset file from its validated name
+func (fs *FilesystemService) assetPath(name string) (string, error) {
+	if err := ValidateAssetName(name); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(fs.postsDirectory, AssetsDirectory, filepath.FromSlash(name)), nil
+}
+
+// Helper function to turn a stored post file or revision into its content by reading and verifying the blob it refers to.
+// Files stored before content was addressed by hash hold their content directly.
+func (fs *FilesystemService) resolve(name string, data []byte) (string, error) {
+	hash, ok := parseRef(data)
+	if !ok {
+		return string(data), nil
+	}
+
+	blob, err := fs.readBlob(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read content of %s: %w", name, err)
+	}
+
+	return string(blob), nil
+}
+
+// Helper function to get the path of a blob file from its hash
+func (fs *FilesystemService) blobPath(hash string) string {
+	return filepath.Join(fs.postsDirectory, BlobsDirectory, filepath.FromSlash(blobName(hash)))
+}
+
+// Helper function to read a blob, checking that it still matches its hash
+func (fs *FilesystemService) readBlob(hash string) ([]byte, error) {
+	data, err := os.ReadFile(fs.blobPath(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("blob not found: %s", hash)
+		}
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	if err := verifyBlob(hash, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Helper function to store content as a blob named by its hash. Content that is already stored intact
+// is not written again but has its modification time refreshed, so a garbage collection that started before
+// the new reference was written still sees the blob as recent and leaves it alone. A blob that has been
+// corrupted is replaced.
+func (fs *FilesystemService) writeBlob(content []byte) (string, error) {
+	hash := HashContent(content)
+	if _, err := fs.readBlob(hash); err == nil {
+		now := time.Now()
+		if err := os.Chtimes(fs.blobPath(hash), now, now); err != nil {
+			return "", fmt.Errorf("failed to refresh blob: %w", err)
+		}
+		return hash, nil
+	}
+
+	blobPath := fs.blobPath(hash)
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create blobs directory: %w", err)
+	}
+
+	// Write to a temporary file and rename it into place so a blob is never seen half-written
+	tmp, err := os.CreateTemp(filepath.Dir(blobPath), ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		return "", fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return hash, nil
+}
@@ -4,33 +4,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"net/http"
+	"path"
 	"strings"
+	"time"
+	"website/internal/timeout"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 )
 
+// hashMetadataKey is the object metadata key references keep the hash of their content under
+const hashMetadataKey = "sha256"
+
+// usedMetadataKey is the blob metadata key recording when identical content was last saved again
+const usedMetadataKey = "last-used"
+
 // GCSService implements ContentService for Google Cloud Storage
 type GCSService struct {
 	client     *storage.Client
 	bucketName string
 	prefix     string // Optional prefix for post files (e.g., "posts/")
+	timeouts   timeout.Timeouts
 }
 
 // NewGCSService creates a new GCS-based content service
-func NewGCSService(client *storage.Client, bucketName, prefix string) *GCSService {
+func NewGCSService(client *storage.Client, bucketName, prefix string, timeouts timeout.Timeouts) *GCSService {
 	return &GCSService{
 		client:     client,
 		bucketName: bucketName,
 		prefix:     prefix,
+		timeouts:   timeouts,
 	}
 }
 
 // GetContent retrieves HTML content from Google Cloud Storage
-func (gcs *GCSService) GetContent(filename string) (string, error) {
+func (gcs *GCSService) GetContent(ctx context.Context, filename string) (string, error) {
 	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
-		return "", fmt.Errorf("invalid filename: %s", filename)
+	if err := ValidateContentKey(filename); err != nil {
+		return "", err
 	}
 
 	// Construct the object path
@@ -40,7 +55,8 @@ func (gcs *GCSService) GetContent(filename string) (string, error) {
 	}
 
 	// Get the object from GCS
-	ctx := context.Background()
+	ctx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
 	bucket := gcs.client.Bucket(gcs.bucketName)
 	obj := bucket.Object(objectPath)
 
@@ -60,14 +76,14 @@ func (gcs *GCSService) GetContent(filename string) (string, error) {
 		return "", fmt.Errorf("failed to read GCS object content: %w", err)
 	}
 
-	return string(content), nil
+	return gcs.resolve(ctx, filename, content)
 }
 
 // SaveContent saves HTML content to Google Cloud Storage
-func (gcs *GCSService) SaveContent(filename, content string) error {
+func (gcs *GCSService) SaveContent(ctx context.Context, filename, content string) error {
 	// Security check: prevent directory traversal
-	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
-		return fmt.Errorf("invalid filename: %s", filename)
+	if err := ValidateContentKey(filename); err != nil {
+		return err
 	}
 	
 	// Construct the object path
@@ -76,25 +92,426 @@ func (gcs *GCSService) SaveContent(filename, content string) error {
 		objectPath = gcs.prefix + filename
 	}
 	
-	// Get the object from GCS
-	ctx := context.Background()
-	bucket := gcs.client.Bucket(gcs.bucketName)
-	obj := bucket.Object(objectPath)
-	
-	// Create a writer for the object
-	writer := obj.NewWriter(ctx)
-	writer.ContentType = "text/html"
+	// Store the content itself once, under its hash
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	hash, err := gcs.writeBlob(ctx, []byte(content))
+	if err != nil {
+		return err
+	}
 	
-	// Write the content
-	if _, err := writer.Write([]byte(content)); err != nil {
+	return gcs.writeRef(ctx, objectPath, hash)
+}
+
+// SaveRevision copies a post object, normally a reference to its content, to the revisions folder within the bucket prefix
+func (gcs *GCSService) SaveRevision(ctx context.Context, filename, revision string) error {
+	// Security check: prevent directory traversal
+	if err := ValidateContentKey(filename); err != nil {
+		return err
+	}
+	if revision == "" || strings.Contains(revision, "..") || strings.Contains(revision, "/") {
+		return fmt.Errorf("invalid revision name: %s", revision)
+	}
+
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	bucket := gcs.client.Bucket(gcs.bucketName)
+	src := bucket.Object(gcs.prefix + filename)
+	dst := bucket.Object(gcs.prefix + RevisionsDirectory + "/" + revision)
+
+	// Copy server-side so the content never has to pass through this process
+	if _, err := dst.CopierFrom(src).Run(ctx); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return fmt.Errorf("post content not found: %s", filename)
+		}
+		return fmt.Errorf("failed to copy GCS object to revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevision retrieves a stored revision from the revisions folder within the bucket prefix
+func (gcs *GCSService) GetRevision(ctx context.Context, revision string) (string, error) {
+	if revision == "" || strings.Contains(revision, "..") || strings.Contains(revision, "/") {
+		return "", fmt.Errorf("invalid revision name: %s", revision)
+	}
+
+	ctx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
+	obj := gcs.client.Bucket(gcs.bucketName).Object(gcs.prefix + RevisionsDirectory + "/" + revision)
+
+	reader, err := obj.NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return "", fmt.Errorf("post revision not found: %s", revision)
+		}
+		return "", fmt.Errorf("failed to open GCS object: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GCS object content: %w", err)
+	}
+
+	return gcs.resolve(ctx, revision, content)
+}
+
+// CopyContent points another name at a post object's content. Content stored before it was addressed
+// by hash is stored as a blob on the way.
+func (gcs *GCSService) CopyContent(ctx context.Context, from, to string) error {
+	if err := ValidateContentKey(from); err != nil {
+		return err
+	}
+	if err := ValidateContentKey(to); err != nil {
+		return err
+	}
+
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	reader, err := gcs.client.Bucket(gcs.bucketName).Object(gcs.prefix + from).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return fmt.Errorf("post content not found: %s", from)
+		}
+		return fmt.Errorf("failed to open GCS object: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read GCS object content: %w", err)
+	}
+
+	// A reference can be copied as it is without downloading its blob
+	if hash, ok := parseRef(data); ok {
+		return gcs.writeRef(ctx, gcs.prefix+to, hash)
+	}
+
+	return gcs.SaveContent(ctx, to, string(data))
+}
+
+// ContentHash reads the hash a post object refers to from its metadata
+func (gcs *GCSService) ContentHash(ctx context.Context, filename string) (string, error) {
+	if err := ValidateContentKey(filename); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
+	attrs, err := gcs.client.Bucket(gcs.bucketName).Object(gcs.prefix + filename).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return "", fmt.Errorf("post content not found: %s", filename)
+		}
+		return "", fmt.Errorf("failed to get GCS object attributes: %w", err)
+	}
+
+	return attrs.Metadata[hashMetadataKey], nil
+}
+
+// DeleteContent removes a post object from Google Cloud Storage
+func (gcs *GCSService) DeleteContent(ctx context.Context, filename string) error {
+	// Security check: prevent directory traversal
+	if err := ValidateContentKey(filename); err != nil {
+		return err
+	}
+
+	return gcs.deleteObject(ctx, gcs.prefix+filename)
+}
+
+// DeleteRevision removes a stored revision from the revisions folder within the bucket prefix
+func (gcs *GCSService) DeleteRevision(ctx context.Context, revision string) error {
+	if revision == "" || strings.Contains(revision, "..") || strings.Contains(revision, "/") {
+		return fmt.Errorf("invalid revision name: %s", revision)
+	}
+
+	return gcs.deleteObject(ctx, gcs.prefix+RevisionsDirectory+"/"+revision)
+}
+
+// CollectGarbage lists the objects within the bucket prefix once, collecting the hashes references carry
+// in their metadata, then removes the blobs none of them refer to
+func (gcs *GCSService) CollectGarbage(ctx context.Context) (int, error) {
+	blobsBase := gcs.prefix + BlobsDirectory + "/"
+	assetsBase := gcs.assetPath("")
+
+	referenced := map[string]bool{}
+	blobs := map[string]*storage.ObjectAttrs{}
+
+	// The listing is one read, while each removal below is a write of its own
+	listCtx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
+
+	iter := gcs.client.Bucket(gcs.bucketName).Objects(listCtx, &storage.Query{Prefix: gcs.prefix})
+	for {
+		attrs, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to list GCS objects: %w", err)
+		}
+
+		switch {
+		case strings.HasPrefix(attrs.Name, blobsBase):
+			blobs[attrs.Name] = attrs
+		case strings.HasPrefix(attrs.Name, assetsBase):
+		default:
+			if hash := attrs.Metadata[hashMetadataKey]; hash != "" {
+				referenced[hash] = true
+			}
+		}
+	}
+
+	// Reusing a blob updates it, so a blob is only removed if it has not been touched since the listing either
+	cutoff := time.Now().Add(-BlobGracePeriod)
+	removed := 0
+	for name, attrs := range blobs {
+		if referenced[path.Base(name)] || attrs.Updated.After(cutoff) {
+			continue
+		}
+
+		deleted, err := gcs.deleteBlob(ctx, name, attrs.Metageneration)
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// Helper function to delete a blob unless it has been updated since garbage collection listed it, reporting
+// whether it was deleted
+func (gcs *GCSService) deleteBlob(ctx context.Context, objectPath string, metageneration int64) (bool, error) {
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	obj := gcs.client.Bucket(gcs.bucketName).Object(objectPath)
+	err := obj.If(storage.Conditions{MetagenerationMatch: metageneration}).Delete(ctx)
+
+	var apiErr *googleapi.Error
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, storage.ErrObjectNotExist):
+		return false, nil
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed:
+		// The blob was reused while garbage collection was running
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to delete GCS object: %w", err)
+	}
+}
+
+// Helper function to delete an object, treating one that is already gone as deleted
+func (gcs *GCSService) deleteObject(ctx context.Context, objectPath string) error {
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	err := gcs.client.Bucket(gcs.bucketName).Object(objectPath).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete GCS object: %w", err)
+	}
+
+	return nil
+}
+
+// SaveAsset uploads an asset to the assets folder within the bucket prefix
+func (gcs *GCSService) SaveAsset(ctx context.Context, name string, data []byte) error {
+	if err := ValidateAssetName(name); err != nil {
+		return err
+	}
+
+	ctx, cancel := gcs.timeouts.ForWrite(ctx)
+	defer cancel()
+	writer := gcs.client.Bucket(gcs.bucketName).Object(gcs.assetPath(name)).NewWriter(ctx)
+	writer.ContentType = AssetContentType(name)
+
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write asset to GCS object: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize GCS asset upload: %w", err)
+	}
+
+	return nil
+}
+
+// GetAsset downloads an asset from the assets folder within the bucket prefix
+func (gcs *GCSService) GetAsset(ctx context.Context, name string) (Asset, error) {
+	if err := ValidateAssetName(name); err != nil {
+		return Asset{}, err
+	}
+
+	ctx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
+	reader, err := gcs.client.Bucket(gcs.bucketName).Object(gcs.assetPath(name)).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return Asset{}, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+		}
+		return Asset{}, fmt.Errorf("failed to open GCS object: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Asset{}, fmt.Errorf("failed to read GCS object content: %w", err)
+	}
+
+	return Asset{Name: name, Size: reader.Attrs.Size, Modified: reader.Attrs.LastModified, Data: data}, nil
+}
+
+// ListAssets lists the objects in the assets folder whose names start with prefix
+func (gcs *GCSService) ListAssets(ctx context.Context, prefix string) ([]Asset, error) {
+	ctx, cancel := gcs.timeouts.ForRead(ctx)
+	defer cancel()
+	base := gcs.assetPath("")
+	iter := gcs.client.Bucket(gcs.bucketName).Objects(ctx, &storage.Query{Prefix: base + prefix})
+
+	var assets []Asset
+	for {
+		attrs, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GCS assets: %w", err)
+		}
+
+		assets = append(assets, Asset{
+			Name:     strings.TrimPrefix(attrs.Name, base),
+			Size:     attrs.Size,
+			Modified: attrs.Updated,
+		})
+	}
+
+	// Object listings are returned in lexical order, so the list is already sorted by name
+	return assets, nil
+}
+
+// DeleteAsset removes an asset from the assets folder within the bucket prefix
+func (gcs *GCSService) DeleteAsset(ctx context.Context, name string) error {
+	if err := ValidateAssetName(name); err != nil {
+		return err
+	}
+
+	return gcs.deleteObject(ctx, gcs.assetPath(name))
+}
+
+// Helper function to get the object path of an asset
+func (gcs *GCSService) assetPath(name string) string {
+	return gcs.prefix + AssetsDirectory + "/" + name
+}
+
+// Helper function to get the object path of a blob
+func (gcs *GCSService) blobPath(hash string) string {
+	return gcs.prefix + BlobsDirectory + "/" + blobName(hash)
+}
+
+// Helper function to turn a stored post object or revision into its content by reading and verifying the blob it refers to.
+// Objects stored before content was addressed by hash hold their content directly.
+func (gcs *GCSService) resolve(ctx context.Context, name string, data []byte) (string, error) {
+	hash, ok := parseRef(data)
+	if !ok {
+		return string(data), nil
+	}
+
+	reader, err := gcs.client.Bucket(gcs.bucketName).Object(gcs.blobPath(hash)).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return "", fmt.Errorf("failed to read content of %s: blob not found: %s", name, hash)
+		}
+		return "", fmt.Errorf("failed to open GCS blob: %w", err)
+	}
+	defer reader.Close()
+
+	blob, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GCS blob: %w", err)
+	}
+
+	if err := verifyBlob(hash, blob); err != nil {
+		return "", fmt.Errorf("failed to read content of %s: %w", name, err)
+	}
+
+	return string(blob), nil
+}
+
+// Helper function to store content as a blob named by its hash. The write only succeeds if no blob with that
+// hash exists yet, so identical content is uploaded at most once, and GCS checks the CRC32C checksum sent along
+// with it so the blob is not stored corrupted. A blob that is already stored is touched instead, so garbage
+// collection sees it as recently used.
+func (gcs *GCSService) writeBlob(ctx context.Context, content []byte) (string, error) {
+	hash := HashContent(content)
+	obj := gcs.client.Bucket(gcs.bucketName).Object(gcs.blobPath(hash))
+
+	stored, err := gcs.uploadBlob(ctx, obj, content)
+	if err != nil {
+		return "", err
+	}
+	if stored {
+		return hash, nil
+	}
+
+	// Updating the metadata moves the blob's update time and metageneration forward
+	_, err = obj.Update(ctx, storage.ObjectAttrsToUpdate{Metadata: map[string]string{usedMetadataKey: time.Now().UTC().Format(time.RFC3339)}})
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		// Garbage collection removed the blob in between, so it is uploaded again
+		if _, err := gcs.uploadBlob(ctx, obj, content); err != nil {
+			return "", err
+		}
+		return hash, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh GCS blob: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Helper function to upload a blob if it doesn't exist yet, reporting whether it was uploaded
+func (gcs *GCSService) uploadBlob(ctx context.Context, obj *storage.ObjectHandle, content []byte) (bool, error) {
+	writer := obj.If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	writer.ContentType = "application/octet-stream"
+	writer.CRC32C = crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli))
+	writer.SendCRC32C = true
+
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return false, fmt.Errorf("failed to write content to GCS blob: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			// The same content is already stored
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to finalize GCS blob upload: %w", err)
+	}
+
+	return true, nil
+}
+
+// Helper function to write a reference to a blob, recording its hash in the object's metadata too
+// so garbage collection can find references from a listing alone
+func (gcs *GCSService) writeRef(ctx context.Context, objectPath, hash string) error {
+	writer := gcs.client.Bucket(gcs.bucketName).Object(objectPath).NewWriter(ctx)
+	writer.ContentType = "text/plain; charset=utf-8"
+	writer.Metadata = map[string]string{hashMetadataKey: hash}
+
+	if _, err := writer.Write(formatRef(hash)); err != nil {
 		writer.Close()
 		return fmt.Errorf("failed to write content to GCS object: %w", err)
 	}
-	
-	// Close the writer to finalize the upload
+
 	if err := writer.Close(); err != nil {
 		return fmt.Errorf("failed to finalize GCS object upload: %w", err)
 	}
-	
+
 	return nil
 }
@@ -1,12 +1,63 @@
 package content
 
+import "context"
+
+// RevisionsDirectory is where backends keep versioned copies of post content, relative to their post storage
+const RevisionsDirectory = "revisions"
+
 // ContentService defines the interface for retrieving and storing blog post content
+// Every method takes the context of the request or job it runs for and gives up once it is cancelled
 type ContentService interface {
 	// GetContent retrieves the HTML content for a blog post by filename
-	// Returns the raw HTML content or an error if the file cannot be found/read
-	GetContent(filename string) (string, error)
+	// Returns the raw HTML content or an error if the file cannot be found/read,
+	// wrapping ErrCorruptContent if the stored content no longer matches its hash
+	GetContent(ctx context.Context, filename string) (string, error)
 	
 	// SaveContent saves HTML content to storage with the given filename
+	// The content is stored once by its SHA-256 hash and the filename refers to it, so identical content is not stored twice
 	// Returns an error if the content cannot be saved
-	SaveContent(filename, content string) error
-}
\ No newline at end of file
+	SaveContent(ctx context.Context, filename, content string) error
+
+	// SaveRevision copies the current content of filename into revision storage under the given name,
+	// keeping a versioned copy that later saves to filename do not overwrite
+	SaveRevision(ctx context.Context, filename, revision string) error
+
+	// GetRevision retrieves a stored revision by name
+	// Returns the source exactly as it was saved, without any rendering
+	GetRevision(ctx context.Context, revision string) (string, error)
+
+	// CopyContent copies a stored content file to another filename without rendering it
+	// Returns an error if the source cannot be found/read
+	CopyContent(ctx context.Context, from, to string) error
+
+	// ContentHash returns the SHA-256 hash of the content the file with the given filename refers to
+	// Returns an empty hash for content stored before content was addressed by hash
+	ContentHash(ctx context.Context, filename string) (string, error)
+
+	// DeleteContent permanently removes the content file with the given filename
+	// Deleting a file that does not exist is not an error; the content itself is removed by CollectGarbage
+	DeleteContent(ctx context.Context, filename string) error
+
+	// DeleteRevision permanently removes a stored revision by name
+	// Deleting a revision that does not exist is not an error
+	DeleteRevision(ctx context.Context, revision string) error
+
+	// CollectGarbage permanently removes stored content that no content file or revision refers to any more,
+	// skipping content saved within BlobGracePeriod
+	// Returns the number of blobs removed
+	CollectGarbage(ctx context.Context) (int, error)
+
+	// SaveAsset stores an uploaded image or attachment under a slash-separated name such as "12/diagram.png"
+	// Returns an error if the name is invalid or the asset cannot be saved
+	SaveAsset(ctx context.Context, name string, data []byte) error
+
+	// GetAsset retrieves a stored asset by name along with its size and modification time
+	GetAsset(ctx context.Context, name string) (Asset, error)
+
+	// ListAssets returns the assets whose names start with prefix, sorted by name and without their data
+	ListAssets(ctx context.Context, prefix string) ([]Asset, error)
+
+	// DeleteAsset permanently removes a stored asset by name
+	// Deleting an asset that does not exist is not an error
+	DeleteAsset(ctx context.Context, name string) error
+}
@@ -0,0 +1,63 @@
+package content
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PostContentKey returns the key a post's content file is stored under. Each post gets its own directory,
+// so posts uploaded with the same filename can't overwrite each other, and the key keeps the file's
+// extension, which decides whether it is rendered as Markdown.
+func PostContentKey(postID int, filename string) string {
+	return fmt.Sprintf("%d/post%s", postID, strings.ToLower(path.Ext(filename)))
+}
+
+// versionLength is how many characters of the content hash name a version of a post's content
+const versionLength = 12
+
+// VersionedContentKey returns the key one version of a post's content is stored under, named after the
+// content's hash. New content gets a key of its own, so it can be stored before the post is pointed at it
+// without touching the content the post shows until then, while uploading the same content again reuses its key.
+func VersionedContentKey(postID int, filename, document string) string {
+	version := HashContent([]byte(document))[:versionLength]
+	return fmt.Sprintf("%d/post-%s%s", postID, version, strings.ToLower(path.Ext(filename)))
+}
+
+// IsPostContentKey reports whether key is a key of the post's own, as given by PostContentKey or VersionedContentKey
+func IsPostContentKey(postID int, key string) bool {
+	dir, name := path.Split(key)
+	if dir != fmt.Sprintf("%d/", postID) || !strings.HasPrefix(name, "post") {
+		return false
+	}
+
+	stem := strings.TrimSuffix(name, path.Ext(name))
+	if stem == "post" {
+		return true
+	}
+
+	version, ok := strings.CutPrefix(stem, "post-")
+	return ok && len(version) == versionLength && isHex(version)
+}
+
+// ValidateContentKey checks that a content filename stays inside post storage and clear of the revisions,
+// assets and blobs directories. Keys are either per-post keys such as "12/post.html" or the flat filenames
+// posts were stored under before keys existed.
+func ValidateContentKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("invalid filename: %s", key)
+	}
+
+	segments := strings.Split(key, "/")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid filename: %s", key)
+		}
+	}
+
+	if len(segments) > 1 && (segments[0] == RevisionsDirectory || segments[0] == AssetsDirectory || segments[0] == BlobsDirectory) {
+		return fmt.Errorf("invalid filename: %s", key)
+	}
+
+	return nil
+}
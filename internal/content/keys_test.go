@@ -0,0 +1,74 @@
+package content
+
+import "testing"
+
+func TestPostContentKey(t *testing.T) {
+	tests := map[string]string{
+		"index.html":   "12/post.html",
+		"Notes.MD":     "12/post.md",
+		"a.b.markdown": "12/post.markdown",
+	}
+
+	for filename, expected := range tests {
+		if got := PostContentKey(12, filename); got != expected {
+			t.Errorf("expected key %q for %q, got %q", expected, filename, got)
+		}
+	}
+}
+
+func TestVersionedContentKey(t *testing.T) {
+	key := VersionedContentKey(12, "Notes.MD", "# Notes")
+	if key != "12/post-"+HashContent([]byte("# Notes"))[:12]+".md" {
+		t.Errorf("unexpected versioned key %q", key)
+	}
+	if other := VersionedContentKey(12, "Notes.MD", "# Changed"); other == key {
+		t.Error("expected different content to get a different key")
+	}
+
+	tests := map[string]bool{
+		key:                true,
+		"12/post.html":     true,
+		"13/post.html":     false,
+		"12/post-xyz.html": false,
+		"12/index.html":    false,
+		"hello-world.html": false,
+		"1/2/post.html":    false,
+	}
+	for k, expected := range tests {
+		if got := IsPostContentKey(12, k); got != expected {
+			t.Errorf("IsPostContentKey(12, %q) = %v, expected %v", k, got, expected)
+		}
+	}
+}
+
+func TestValidateContentKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"12/post.html", true},
+		{"hello-world.html", true},
+		{"12/index.md", true},
+		{"", false},
+		{"/12/post.html", false},
+		{"../secret.html", false},
+		{"12/../../secret.html", false},
+		{"12//post.html", false},
+		{`12\post.html`, false},
+		{"revisions/1-2-post.html", false},
+		{"blobs/ab/abcdef", false},
+		{"assets/12/photo.png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := ValidateContentKey(tt.key)
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.key, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected %q to be rejected", tt.key)
+			}
+		})
+	}
+}
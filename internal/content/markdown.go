@@ -0,0 +1,99 @@
+package content
+
+import (
+	"context"
+	"fmt"
+
+	"website/internal/markdown"
+)
+
+// MarkdownService decorates a ContentService so Markdown sources are rendered to HTML on read
+type MarkdownService struct {
+	next ContentService
+}
+
+// NewMarkdownService wraps an existing content service with Markdown rendering
+func NewMarkdownService(next ContentService) *MarkdownService {
+	return &MarkdownService{
+		next: next,
+	}
+}
+
+// GetContent retrieves content from the wrapped service, rendering .md files to HTML
+func (ms *MarkdownService) GetContent(ctx context.Context, filename string) (string, error) {
+	content, err := ms.next.GetContent(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+
+	if !markdown.IsMarkdown(filename) {
+		return content, nil
+	}
+
+	doc, err := markdown.Render(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to render markdown content %s: %w", filename, err)
+	}
+
+	return doc.HTML, nil
+}
+
+// SaveContent stores the source unchanged so Markdown files keep their original text
+func (ms *MarkdownService) SaveContent(ctx context.Context, filename, content string) error {
+	return ms.next.SaveContent(ctx, filename, content)
+}
+
+// SaveRevision passes through to the wrapped service
+func (ms *MarkdownService) SaveRevision(ctx context.Context, filename, revision string) error {
+	return ms.next.SaveRevision(ctx, filename, revision)
+}
+
+// GetRevision returns the stored source unchanged so revisions can be diffed and restored
+func (ms *MarkdownService) GetRevision(ctx context.Context, revision string) (string, error) {
+	return ms.next.GetRevision(ctx, revision)
+}
+
+// CopyContent copies the stored source unchanged
+func (ms *MarkdownService) CopyContent(ctx context.Context, from, to string) error {
+	return ms.next.CopyContent(ctx, from, to)
+}
+
+// ContentHash passes through to the wrapped service
+func (ms *MarkdownService) ContentHash(ctx context.Context, filename string) (string, error) {
+	return ms.next.ContentHash(ctx, filename)
+}
+
+// DeleteContent passes through to the wrapped service
+func (ms *MarkdownService) DeleteContent(ctx context.Context, filename string) error {
+	return ms.next.DeleteContent(ctx, filename)
+}
+
+// DeleteRevision passes through to the wrapped service
+func (ms *MarkdownService) DeleteRevision(ctx context.Context, revision string) error {
+	return ms.next.DeleteRevision(ctx, revision)
+}
+
+// CollectGarbage passes through to the wrapped service
+func (ms *MarkdownService) CollectGarbage(ctx context.Context) (int, error) {
+	return ms.next.CollectGarbage(ctx)
+}
+
+// SaveAsset passes through to the wrapped service
+func (ms *MarkdownService) SaveAsset(ctx context.Context, name string, data []byte) error {
+	return ms.next.SaveAsset(ctx, name, data)
+}
+
+// GetAsset passes through to the wrapped service
+func (ms *MarkdownService) GetAsset(ctx context.Context, name string) (Asset, error) {
+	return ms.next.GetAsset(ctx, name)
+}
+
+// ListAssets passes through to the wrapped service
+func (ms *MarkdownService) ListAssets(ctx context.Context, prefix string) ([]Asset, error) {
+	return ms.next.ListAssets(ctx, prefix)
+}
+
+// DeleteAsset passes through to the wrapped service
+func (ms *MarkdownService) DeleteAsset(ctx context.Context, name string) error {
+	return ms.next.DeleteAsset(ctx, name)
+}
@@ -0,0 +1,279 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(sql string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(sql)} }
+
+	t.Run("sorted by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0010_add_index.up.sql":   file("CREATE INDEX"),
+			"m/0010_add_index.down.sql": file("DROP INDEX"),
+			"m/0002_add_table.up.sql":   file("CREATE TABLE"),
+			"m/0002_add_table.down.sql": file("DROP TABLE"),
+		}
+
+		migrations, err := loadMigrations(fsys, "m")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Version != 10 {
+			t.Fatalf("expected versions 2 and 10 in order, got %+v", migrations)
+		}
+		if migrations[0].Name != "add_table" || migrations[0].Up != "CREATE TABLE" || migrations[0].Down != "DROP TABLE" {
+			t.Errorf("unexpected migration %+v", migrations[0])
+		}
+	})
+
+	invalid := map[string]fstest.MapFS{
+		"missing down": {
+			"m/0001_init.up.sql": file("CREATE TABLE"),
+		},
+		"duplicate version": {
+			"m/0001_init.up.sql":    file("CREATE TABLE"),
+			"m/0001_init.down.sql":  file("DROP TABLE"),
+			"m/0001_other.up.sql":   file("CREATE INDEX"),
+			"m/0001_other.down.sql": file("DROP INDEX"),
+		},
+		"bad name": {
+			"m/init.sql": file("CREATE TABLE"),
+		},
+		"empty": {
+			"m": &fstest.MapFile{Mode: fs.ModeDir},
+		},
+	}
+
+	for name, fsys := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadMigrations(fsys, "m"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	t.Run("embedded migrations are valid", func(t *testing.T) {
+		if _, err := NewMigrator(nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// Helper function to build a migrator with two migrations on a mock connection
+func newMockMigrator(t *testing.T) (*Migrator, pgxmock.PgxConnIface) {
+	mock, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create mock connection: %v", err)
+	}
+	t.Cleanup(func() { mock.Close(context.Background()) })
+
+	return &Migrator{conn: mock, migrations: []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE one", Down: "DROP TABLE one"},
+		{Version: 2, Name: "more", Up: "CREATE TABLE two", Down: "DROP TABLE two"},
+	}}, mock
+}
+
+// Helper function to expect the lock, table and applied versions read that start every run
+func expectLocked(mock pgxmock.PgxConnIface, applied ...int) {
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS public.schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE", 0))
+
+	rows := pgxmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, time.Now())
+	}
+	mock.ExpectQuery("SELECT version, applied_at FROM public.schema_migrations").WillReturnRows(rows)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Run("applies only pending migrations", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+
+		expectLocked(mock, 1)
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE two").WillReturnResult(pgxmock.NewResult("CREATE", 0))
+		mock.ExpectExec("INSERT INTO public.schema_migrations").WithArgs(2, "more").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		applied, err := m.Up(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if applied != 1 {
+			t.Errorf("expected 1 migration applied, got %d", applied)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("failed migration is rolled back and stops the run", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+
+		expectLocked(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE one").WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		applied, err := m.Up(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "0001_init") {
+			t.Fatalf("expected an error naming the migration, got %v", err)
+		}
+		if applied != 0 {
+			t.Errorf("expected no migrations applied, got %d", applied)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	t.Run("reverts the newest applied migration", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+
+		expectLocked(mock, 1, 2)
+		mock.ExpectBegin()
+		mock.ExpectExec("DROP TABLE two").WillReturnResult(pgxmock.NewResult("DROP", 0))
+		mock.ExpectExec("DELETE FROM public.schema_migrations").WithArgs(2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectCommit()
+		mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		reverted, err := m.Down(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reverted != 1 {
+			t.Errorf("expected 1 migration reverted, got %d", reverted)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("refuses when the database is ahead of the build", func(t *testing.T) {
+		m, mock := newMockMigrator(t)
+
+		expectLocked(mock, 1, 2, 3)
+		mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		if _, err := m.Down(context.Background(), 1); err == nil {
+			t.Fatal("expected an error")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+// TestInitialSchema_AdoptsBaselineTable replays the first migration's posts statements against the columns of the
+// posts table created by the original database/init.sql. CREATE TABLE IF NOT EXISTS leaves that table alone, so
+// the migration has to add every newer column itself before any index uses it, without stamping the posts already
+// there with the time of the migration.
+func TestInitialSchema_AdoptsBaselineTable(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var (
+		createTable = regexp.MustCompile(`(?is)^CREATE TABLE IF NOT EXISTS public\.posts \((.*)\)$`)
+		addColumn   = regexp.MustCompile(`(?i)ADD COLUMN IF NOT EXISTS (\w+)([^,]*)`)
+		setNotNull  = regexp.MustCompile(`(?i)ALTER COLUMN (\w+) SET NOT NULL`)
+		backfill    = regexp.MustCompile(`(?i)^UPDATE public\.posts SET (\w+) = .* WHERE (\w+) IS NULL$`)
+		clockTime   = regexp.MustCompile(`(?i)CURRENT_TIMESTAMP|now\(\)`)
+		createIndex = regexp.MustCompile(`(?is)^CREATE (?:UNIQUE )?INDEX IF NOT EXISTS \w+ ON public\.posts \(([^)]*)\)(?: WHERE (\w+))?`)
+	)
+
+	baseline := map[string]bool{"id": true, "title": true, "author": true, "created": true, "edited": true, "body": true, "description": true}
+	columns := maps.Clone(baseline)
+	nullable := map[string]bool{} // columns added without a value for the existing posts
+	backfilled := map[string]bool{}
+	var fresh []string
+
+	for _, statement := range strings.Split(migrations[0].Up, ";") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+				lines = append(lines, line)
+			}
+		}
+		statement = strings.TrimSpace(strings.Join(lines, "\n"))
+
+		if match := createTable.FindStringSubmatch(statement); match != nil {
+			// The table already exists, so only note what a fresh database would have
+			for _, definition := range strings.Split(match[1], ",\n") {
+				fresh = append(fresh, strings.Fields(definition)[0])
+			}
+			continue
+		}
+
+		if strings.HasPrefix(statement, "ALTER TABLE public.posts") {
+			for _, match := range addColumn.FindAllStringSubmatch(statement, -1) {
+				column, definition := match[1], match[2]
+				columns[column] = true
+
+				if clockTime.MatchString(definition) {
+					t.Errorf("column %s would give existing posts the time of the migration: %s", column, definition)
+				}
+				if !strings.Contains(strings.ToLower(definition), "default") {
+					nullable[column] = true
+				}
+			}
+
+			for _, match := range setNotNull.FindAllStringSubmatch(statement, -1) {
+				if nullable[match[1]] && !backfilled[match[1]] {
+					t.Errorf("column %s is made not null before existing posts get a value", match[1])
+				}
+			}
+			continue
+		}
+
+		if match := backfill.FindStringSubmatch(statement); match != nil && match[1] == match[2] {
+			if !columns[match[1]] {
+				t.Errorf("column %s is filled in before it exists: %s", match[1], statement)
+			}
+			backfilled[match[1]] = true
+			continue
+		}
+
+		if match := createIndex.FindStringSubmatch(statement); match != nil {
+			used := []string{match[2]}
+			for _, column := range strings.Split(match[1], ",") {
+				used = append(used, strings.Fields(column)[0])
+			}
+			for _, column := range used {
+				if column != "" && !columns[column] {
+					t.Errorf("index uses column %s before it exists on a baseline table: %s", column, statement)
+				}
+			}
+		}
+	}
+
+	if len(fresh) == 0 {
+		t.Fatal("expected the migration to create the posts table")
+	}
+	for _, column := range fresh {
+		if !columns[column] {
+			t.Errorf("column %s is created on fresh databases but never added to baseline ones", column)
+		}
+	}
+
+	if !backfilled["publish_at"] {
+		t.Error("expected existing posts to get a publish time from when they were created")
+	}
+}
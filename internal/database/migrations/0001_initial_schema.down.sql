@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.post_revisions;
+DROP TABLE IF EXISTS public.post_search;
+DROP TABLE IF EXISTS public.post_tags;
+DROP TABLE IF EXISTS public.tags;
+DROP TABLE IF EXISTS public.posts;
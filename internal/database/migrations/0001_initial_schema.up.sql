@@ -0,0 +1,79 @@
+-- Baseline schema. Everything is only created if missing, so databases set up from database/init.sql
+-- before migrations existed are adopted, with any posts columns they lack added.
+
+CREATE TABLE IF NOT EXISTS public.posts (
+    id serial primary key,
+    title varchar(80),
+    author varchar(80) default 'Adam Shkolnik',
+    created timestamp default CURRENT_TIMESTAMP,
+    edited timestamp default  CURRENT_TIMESTAMP,
+    body varchar(80),
+    description varchar(500),
+    slug varchar(100) not null default '',
+    status varchar(20) not null default 'published',
+    publish_at timestamp not null default CURRENT_TIMESTAMP,
+    deleted_at timestamp,
+    trusted boolean not null default false,
+    sanitized text[] not null default '{}',
+    filename varchar not null default ''
+);
+
+-- Databases created from the original database/init.sql have a posts table without the columns added since,
+-- which CREATE TABLE IF NOT EXISTS leaves alone, so they are added here before the indexes that use them
+ALTER TABLE public.posts
+    ADD COLUMN IF NOT EXISTS slug varchar(100) not null default '',
+    ADD COLUMN IF NOT EXISTS status varchar(20) not null default 'published',
+    ADD COLUMN IF NOT EXISTS publish_at timestamp,
+    ADD COLUMN IF NOT EXISTS deleted_at timestamp,
+    ADD COLUMN IF NOT EXISTS trusted boolean not null default false,
+    ADD COLUMN IF NOT EXISTS sanitized text[] not null default '{}',
+    ADD COLUMN IF NOT EXISTS filename varchar not null default '';
+
+-- Existing posts were published when they were created, not when the column was added
+UPDATE public.posts SET publish_at = COALESCE(created, CURRENT_TIMESTAMP) WHERE publish_at IS NULL;
+
+ALTER TABLE public.posts
+    ALTER COLUMN publish_at SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN publish_at SET NOT NULL;
+
+-- Slugs are unique once assigned; posts created before slugs existed keep an empty slug
+CREATE UNIQUE INDEX IF NOT EXISTS posts_slug_key ON public.posts (slug) WHERE slug <> '';
+
+-- Public post lists are paginated by keyset on (created, id), newest first
+CREATE INDEX IF NOT EXISTS posts_created_id_idx ON public.posts (created DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS public.tags (
+    id serial primary key,
+    name varchar(50) not null unique
+);
+
+CREATE TABLE IF NOT EXISTS public.post_tags (
+    post_id integer not null references public.posts (id) on delete cascade,
+    tag_id integer not null references public.tags (id) on delete cascade,
+    primary key (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON public.post_tags (tag_id);
+
+-- Full-text search document per post: title (A), description (B) and the plain text of the post content (C)
+CREATE TABLE IF NOT EXISTS public.post_search (
+    post_id integer primary key references public.posts (id) on delete cascade,
+    body text not null default '',
+    document tsvector not null
+);
+
+CREATE INDEX IF NOT EXISTS post_search_document_idx ON public.post_search USING GIN (document);
+
+CREATE TABLE IF NOT EXISTS public.post_revisions (
+    post_id integer not null references public.posts (id) on delete cascade,
+    number integer not null,
+    title varchar not null,
+    description varchar not null default '',
+    body varchar not null,
+    slug varchar not null default '',
+    tags text[] not null default '{}',
+    content varchar not null,
+    note varchar not null default '',
+    created timestamp not null default now(),
+    primary key (post_id, number)
+);
@@ -0,0 +1,249 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of change a diff line represents
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is a single line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// ContextLines is the number of unchanged lines shown around each change in a unified diff
+const ContextLines = 3
+
+// Lines computes a shortest line diff from a to b using the linear space variant of Myers' algorithm: the
+// middle of an optimal path is found by searching from both ends at once, then each half is diffed the same way.
+// Memory stays proportional to n+m, while time grows with (n+m) times the number of differences.
+func Lines(a, b []string) []Line {
+	return compare(nil, a, b)
+}
+
+// Helper function to append the diff of a and b to lines, splitting the problem at a point on an optimal path
+func compare(lines []Line, a, b []string) []Line {
+	// Lines the texts share at either end are unchanged, whatever happens between them
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		lines = append(lines, Line{Equal, a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	if x, y, ok := bisect(a, b); ok {
+		lines = compare(lines, a[:x], b[:y])
+		lines = compare(lines, a[x:], b[y:])
+	} else {
+		// Nothing in common is left, so every line of a is deleted and every line of b inserted
+		for _, text := range a {
+			lines = append(lines, Line{Delete, text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Insert, text})
+		}
+	}
+
+	for _, text := range common {
+		lines = append(lines, Line{Equal, text})
+	}
+	return lines
+}
+
+// Helper function to find where the forward and backward searches for a shortest edit path meet. The path
+// through (x, y) is optimal, so a[:x] against b[:y] and a[x:] against b[y:] can be diffed separately. Reports
+// false when the texts have no line in common.
+func bisect(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+
+	// forward and backward keep the furthest reaching x on every diagonal, counted from each end
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the paths meet during a forward step, otherwise during a backward one
+	odd := delta%2 != 0
+
+	// Diagonals that ran off the edges of the grid are skipped from then on
+	kForwardStart, kForwardEnd, kBackwardStart, kBackwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + kForwardStart; k <= d-kForwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				kForwardEnd += 2
+			case y > m:
+				kForwardStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + kBackwardStart; k <= d-kBackwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				kBackwardEnd += 2
+			case y > m:
+				kBackwardStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					fy := fx - (j - offset)
+					if fx >= n-x {
+						return fx, fy, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// Unified returns a unified diff of two texts, or an empty string when they are the same
+func Unified(fromName, toName, a, b string) string {
+	lines := Lines(splitLines(a), splitLines(b))
+
+	var changes []int
+	for i, line := range lines {
+		if line.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Changes closer together than twice the context share a hunk
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end] <= 2*ContextLines {
+			end++
+		}
+
+		first := changes[start] - ContextLines
+		if first < 0 {
+			first = 0
+		}
+		last := changes[end] + ContextLines + 1
+		if last > len(lines) {
+			last = len(lines)
+		}
+
+		writeHunk(&out, lines, first, last)
+		start = end + 1
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, lines []Line, first, last int) {
+	// Line numbers in the hunk header are 1-based positions in each text
+	aStart, bStart := 1, 1
+	for _, line := range lines[:first] {
+		if line.Op != Insert {
+			aStart++
+		}
+		if line.Op != Delete {
+			bStart++
+		}
+	}
+
+	aLen, bLen := 0, 0
+	for _, line := range lines[first:last] {
+		if line.Op != Insert {
+			aLen++
+		}
+		if line.Op != Delete {
+			bLen++
+		}
+	}
+
+	// An empty range starts at the line before it
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, line := range lines[first:last] {
+		switch line.Op {
+		case Equal:
+			out.WriteString(" ")
+		case Insert:
+			out.WriteString("+")
+		case Delete:
+			out.WriteString("-")
+		}
+		out.WriteString(line.Text)
+		out.WriteString("\n")
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
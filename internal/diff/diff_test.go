@@ -0,0 +1,162 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []string
+		expected []Line
+	}{
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"insert", []string{"a", "c"}, []string{"a", "b", "c"}, []Line{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}}},
+		{"delete", []string{"a", "b", "c"}, []string{"a", "c"}, []Line{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}}},
+		{"replace", []string{"a", "b"}, []string{"a", "x"}, []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}}},
+		{"from empty", nil, []string{"a"}, []Line{{Insert, "a"}}},
+		{"to empty", []string{"a"}, nil, []Line{{Delete, "a"}}},
+		{"both empty", nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLines_ReconstructsBothSides(t *testing.T) {
+	a := strings.Split("the quick brown fox jumps over the lazy dog", " ")
+	b := strings.Split("a quick red fox leaps over the dog today", " ")
+
+	var gotA, gotB []string
+	for _, line := range Lines(a, b) {
+		if line.Op != Insert {
+			gotA = append(gotA, line.Text)
+		}
+		if line.Op != Delete {
+			gotB = append(gotB, line.Text)
+		}
+	}
+
+	if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+		t.Errorf("diff does not reconstruct inputs: %v / %v", gotA, gotB)
+	}
+}
+
+func TestLines_Shortest(t *testing.T) {
+	// Random pairs of short texts over a small alphabet, checked against the edit distance from the longest
+	// common subsequence
+	rng := rand.New(rand.NewPCG(1, 2))
+	random := func() []string {
+		text := make([]string, rng.IntN(12))
+		for i := range text {
+			text[i] = string(rune('a' + rng.IntN(3)))
+		}
+		return text
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+
+		edits := 0
+		for _, line := range Lines(a, b) {
+			if line.Op != Equal {
+				edits++
+			}
+		}
+
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("diff of %v and %v has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestLines_LargeDifferentInputs(t *testing.T) {
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old line %d", i)
+		b[i] = fmt.Sprintf("new line %d", i)
+	}
+
+	lines := Lines(a, b)
+	if len(lines) != len(a)+len(b) {
+		t.Fatalf("expected %d lines, got %d", len(a)+len(b), len(lines))
+	}
+	for _, line := range lines {
+		if line.Op == Equal {
+			t.Fatalf("expected no unchanged lines, got %q", line.Text)
+		}
+	}
+}
+
+// Helper function to get the length of the longest common subsequence of two texts
+func lcs(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestUnified(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		if got := Unified("a", "b", "same\n", "same\n"); got != "" {
+			t.Errorf("expected empty diff, got %q", got)
+		}
+	})
+
+	t.Run("single hunk", func(t *testing.T) {
+		got := Unified("revision 1", "revision 2", "one\ntwo\nthree\n", "one\n2\nthree\n")
+		expected := "--- revision 1\n+++ revision 2\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+
+		if got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("distant changes split into hunks", func(t *testing.T) {
+		var a, b []string
+		for i := 0; i < 20; i++ {
+			a = append(a, "line")
+			b = append(b, "line")
+		}
+		a[1], b[1] = "old start", "new start"
+		a[18], b[18] = "old end", "new end"
+
+		got := Unified("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+		if hunks := strings.Count(got, "@@ -"); hunks != 2 {
+			t.Errorf("expected 2 hunks, got %d in %q", hunks, got)
+		}
+		if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -16,5 +16,5 @@") {
+			t.Errorf("unexpected hunk headers in %q", got)
+		}
+	})
+
+	t.Run("added to empty text", func(t *testing.T) {
+		got := Unified("a", "b", "", "new\n")
+		expected := "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n"
+
+		if got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+// Package export renders a site's public routes through its HTTP handler into a directory of static files. Links
+// between exported files are rewritten to relative ones ending in the file name, so the copy works from any
+// path on a static host as well as opened straight from disk.
+package export
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options configure an export
+type Options struct {
+	OutDir    string // directory the files are written to; files already there are overwritten
+	StaticDir string // directory served under /static/, copied as it is; empty skips it
+	BaseURL   string // origin pages are requested from, used by absolute links such as those in feeds
+}
+
+// Result counts what an export wrote
+type Result struct {
+	Pages     int      // HTML pages rendered
+	Redirects int      // routes written as pages sending readers on to where the route redirects
+	Files     int      // other documents and media, written as served
+	Static    int      // files copied from the static directory
+	Failed    []string // routes that could not be rendered, with the reason
+}
+
+// linkAttribute and srcsetAttribute find the attributes whose URLs are rewritten, with either quote style
+var (
+	linkAttribute   = regexp.MustCompile(`(?i)(\s(?:href|src|action|poster)\s*=\s*)("[^"]*"|'[^']*')`)
+	srcsetAttribute = regexp.MustCompile(`(?i)(\ssrcset\s*=\s*)("[^"]*"|'[^']*')`)
+)
+
+// mediaPrefix is where uploaded media is served. Media has no route of its own to export, so it is
+// exported as links to it are found.
+const mediaPrefix = "/media/"
+
+// exporter holds the state of a single export
+type exporter struct {
+	ctx     context.Context
+	handler http.Handler
+	opts    Options
+	files   map[string]string // route keys to the file each is written to, relative to OutDir
+	queue   []string          // routes still to render
+	result  Result
+}
+
+// Export renders the routes through handler into opts.OutDir and copies the static directory alongside them.
+// Routes answering with a redirect become pages that send readers on to the target, and routes that fail are
+// listed in the result rather than stopping the export. Errors are only returned for failures to write files.
+func Export(ctx context.Context, handler http.Handler, routes []string, opts Options) (Result, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "http://localhost"
+	}
+
+	e := &exporter{ctx: ctx, handler: handler, opts: opts, files: make(map[string]string)}
+
+	if opts.StaticDir != "" {
+		if err := e.copyStatic(); err != nil {
+			return e.result, err
+		}
+	}
+
+	// Every route gets its file up front so links resolve whichever page is rendered first
+	for _, route := range routes {
+		u, err := url.Parse(route)
+		if err != nil {
+			e.result.Failed = append(e.result.Failed, fmt.Sprintf("%s: %v", route, err))
+			continue
+		}
+		if _, ok := e.files[routeKey(u)]; ok {
+			continue
+		}
+
+		e.files[routeKey(u)] = filePath(u)
+		e.queue = append(e.queue, route)
+	}
+
+	for len(e.queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return e.result, err
+		}
+
+		route := e.queue[0]
+		e.queue = e.queue[1:]
+		if err := e.render(route); err != nil {
+			return e.result, err
+		}
+	}
+
+	return e.result, nil
+}
+
+// Helper function to render a single route and write it to its file
+func (e *exporter) render(route string) error {
+	u, _ := url.Parse(route)
+	file := e.files[routeKey(u)]
+
+	req, err := http.NewRequestWithContext(e.ctx, http.MethodGet, e.opts.BaseURL+route, nil)
+	if err != nil {
+		e.result.Failed = append(e.result.Failed, fmt.Sprintf("%s: %v", route, err))
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+
+	switch {
+	case rec.Code == http.StatusOK:
+		body := rec.Body.Bytes()
+		if strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			body = e.rewrite(file, body)
+			e.result.Pages++
+		} else {
+			e.result.Files++
+		}
+		return e.write(file, body)
+	case rec.Code >= 300 && rec.Code < 400 && rec.Header().Get("Location") != "":
+		target := e.link(file, html.EscapeString(rec.Header().Get("Location")))
+		e.result.Redirects++
+		return e.write(file, redirectPage(target))
+	default:
+		e.result.Failed = append(e.result.Failed, fmt.Sprintf("%s: %d %s", route, rec.Code, http.StatusText(rec.Code)))
+		return nil
+	}
+}
+
+// Helper function to rewrite the site-relative links of a page written to file
+func (e *exporter) rewrite(file string, body []byte) []byte {
+	replace := func(pattern *regexp.Regexp, rewrite func(string) string) func(string) string {
+		return func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			quote, value := parts[2][:1], parts[2][1:len(parts[2])-1]
+			return parts[1] + quote + rewrite(value) + quote
+		}
+	}
+
+	page := linkAttribute.ReplaceAllStringFunc(string(body), replace(linkAttribute, func(value string) string {
+		return e.link(file, value)
+	}))
+
+	// A srcset lists candidates as "URL width" separated by commas
+	page = srcsetAttribute.ReplaceAllStringFunc(page, replace(srcsetAttribute, func(value string) string {
+		candidates := strings.Split(value, ",")
+		for i, candidate := range candidates {
+			fields := strings.Fields(candidate)
+			if len(fields) > 0 {
+				fields[0] = e.link(file, fields[0])
+				candidates[i] = strings.Join(fields, " ")
+			}
+		}
+		return strings.Join(candidates, ", ")
+	}))
+
+	return []byte(page)
+}
+
+// Helper function to turn an HTML-escaped link on the page written to file into one relative to it. Links to
+// routes that were not exported, such as search, are left pointing at the site; media they reach is exported too.
+func (e *exporter) link(file, value string) string {
+	raw := html.UnescapeString(value)
+	if !strings.HasPrefix(raw, "/") || strings.HasPrefix(raw, "//") {
+		return value
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return value
+	}
+
+	key := routeKey(u)
+	target, ok := e.files[key]
+	if !ok {
+		if !strings.HasPrefix(u.Path, mediaPrefix) {
+			return value
+		}
+
+		target = filePath(u)
+		e.files[key] = target
+		e.queue = append(e.queue, (&url.URL{Path: u.Path}).EscapedPath())
+	}
+
+	link := relativePath(file, target)
+	if u.Fragment != "" {
+		link += "#" + u.EscapedFragment()
+	}
+	return html.EscapeString(link)
+}
+
+// Helper function to copy the static directory into the output under static/, registering each file so links to it
+// are rewritten
+func (e *exporter) copyStatic() error {
+	return filepath.WalkDir(e.opts.StaticDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(e.opts.StaticDir, name)
+		if err != nil {
+			return err
+		}
+		file := path.Join("static", filepath.ToSlash(rel))
+
+		src, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := e.create(file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			dst.Close()
+			return fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+		if err := dst.Close(); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+
+		e.files["/"+file] = file
+		e.result.Static++
+		return nil
+	})
+}
+
+// Helper function to write an exported file
+func (e *exporter) write(file string, data []byte) error {
+	f, err := e.create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return f.Close()
+}
+
+// Helper function to create a file in the output directory along with its parent directories
+func (e *exporter) create(file string) (*os.File, error) {
+	name := filepath.Join(e.opts.OutDir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	return f, nil
+}
+
+// Helper function to identify the file a URL is exported to. Only the path and page number matter, so links
+// carrying a pagination cursor reach the same page as links without one.
+func routeKey(u *url.URL) string {
+	key := path.Clean("/" + u.Path)
+	if page, err := strconv.Atoi(u.Query().Get("page")); err == nil && page > 1 {
+		key += "?page=" + strconv.Itoa(page)
+	}
+	return key
+}
+
+// Helper function to name the file a URL is exported to. Documents and media keep their names; pages become
+// index.html in a directory named after the route, with pages past the first under page/N.
+func filePath(u *url.URL) string {
+	p := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if path.Ext(p) != "" {
+		return p
+	}
+
+	if page, err := strconv.Atoi(u.Query().Get("page")); err == nil && page > 1 {
+		p = path.Join(p, "page", strconv.Itoa(page))
+	}
+	return path.Join(p, "index.html")
+}
+
+// Helper function to build the link from one exported file to another, escaped for use in a URL
+func relativePath(from, to string) string {
+	fromParts := strings.Split(path.Dir(from), "/")
+	if path.Dir(from) == "." {
+		fromParts = nil
+	}
+	toParts := strings.Split(to, "/")
+
+	common := 0
+	for common < len(fromParts) && common < len(toParts)-1 && fromParts[common] == toParts[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(fromParts)-common+len(toParts)-common)
+	for range fromParts[common:] {
+		parts = append(parts, "..")
+	}
+	for _, part := range toParts[common:] {
+		parts = append(parts, url.PathEscape(part))
+	}
+	return strings.Join(parts, "/")
+}
+
+// Helper function to build a page that sends readers on to an exported route's redirect target
+func redirectPage(target string) []byte {
+	return []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Redirecting…</title>
+  <link rel="canonical" href="` + target + `">
+  <meta http-equiv="refresh" content="0; url=` + target + `">
+</head>
+<body>
+  <p>This page has moved to <a href="` + target + `">` + target + `</a>.</p>
+</body>
+</html>
+`)
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Helper function to build a small site: a redirecting root, paginated posts linking to each other with cursors,
+// a post with an image, and a feed
+func testSite() http.Handler {
+	page := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, `<html><head><link rel="stylesheet" href="/static/css/styles.css"></head><body>%s</body></html>`, body)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/about", http.StatusFound)
+	})
+	mux.HandleFunc("GET /about", page(`<a href="/blog/posts">Blog</a> <form action="/blog/search"></form>`))
+	mux.HandleFunc("GET /blog/posts", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			page(`<a href="/blog/posts?page=1&amp;cursor=abc">Previous</a>`)(w, r)
+			return
+		}
+		page(`<a href="/blog/posts?page=2&amp;cursor=xyz">Next</a> <a href="/blog/hello#intro">Hello</a>`)(w, r)
+	})
+	mux.HandleFunc("GET /blog/hello", page(`<img src="/media/1/photo.png" srcset="/media/1/photo-320w.png 320w, https://example.com/x.png 640w"> <a href='/about'>About</a>`))
+	mux.HandleFunc("GET /blog/post/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/blog/hello", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("GET /blog/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss><link>`+r.Host+`/blog/hello</link></rss>`)
+	})
+	mux.HandleFunc("GET /media/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "1/photo.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	})
+	return mux
+}
+
+func TestExport(t *testing.T) {
+	out := t.TempDir()
+	static := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(static, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(static, "css", "styles.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []string{"/", "/about", "/blog/posts", "/blog/posts?page=2", "/blog/post/1", "/blog/hello", "/blog/feed.xml", "/blog/missing"}
+	result, err := Export(context.Background(), testSite(), routes, Options{OutDir: out, StaticDir: static, BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Pages != 4 || result.Redirects != 2 || result.Static != 1 {
+		t.Errorf("unexpected counts %+v", result)
+	}
+	// The feed and the image are written as served; the srcset variant does not exist
+	if result.Files != 2 || len(result.Failed) != 2 {
+		t.Errorf("expected two files and two failures, got %+v", result)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("expected %s to be exported: %v", name, err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"index.html", []string{`url=about/index.html`}},
+		{"about/index.html", []string{`href="../blog/posts/index.html"`, `href="../static/css/styles.css"`, `action="/blog/search"`}},
+		{"blog/posts/index.html", []string{`href="page/2/index.html"`, `href="../hello/index.html#intro"`}},
+		{"blog/posts/page/2/index.html", []string{`href="../../index.html"`}},
+		{"blog/hello/index.html", []string{`src="../../media/1/photo.png"`, `srcset="../../media/1/photo-320w.png 320w, https://example.com/x.png 640w"`, `href='../../about/index.html'`}},
+		{"blog/post/1/index.html", []string{`url=../../hello/index.html`}},
+		{"blog/feed.xml", []string{`<link>example.com/blog/hello</link>`}},
+		{"media/1/photo.png", []string{"png"}},
+		{"static/css/styles.css", []string{"body{}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body := read(tt.file)
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected %s to contain %s, got %s", tt.file, want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	tests := map[string]string{
+		"/":                       "index.html",
+		"/about":                  "about/index.html",
+		"/blog/posts?page=1":      "blog/posts/index.html",
+		"/blog/posts?page=3&c=x":  "blog/posts/page/3/index.html",
+		"/blog/tags/c%23":         "blog/tags/c#/index.html",
+		"/blog/feed.xml":          "blog/feed.xml",
+		"/media/../../etc/passwd": "etc/passwd/index.html",
+	}
+
+	for route, want := range tests {
+		u, err := url.Parse(route)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filePath(u); got != want {
+			t.Errorf("filePath(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct{ from, to, want string }{
+		{"index.html", "about/index.html", "about/index.html"},
+		{"about/index.html", "about/index.html", "index.html"},
+		{"blog/posts/page/2/index.html", "blog/posts/index.html", "../../index.html"},
+		{"blog/tags/go/index.html", "blog/tags/c#/index.html", "../c%23/index.html"},
+	}
+
+	for _, tt := range tests {
+		if got := relativePath(tt.from, tt.to); got != tt.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFeed = Feed{
+	Title:       "Blog",
+	Description: "Latest posts",
+	Link:        "https://example.com/blog/posts",
+	FeedURL:     "https://example.com/blog/feed.xml",
+	Author:      "Adam Shkolnik",
+	Updated:     time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC),
+	Items: []Item{
+		{
+			ID:          "https://example.com/blog/post/1",
+			Title:       "Hello & Welcome",
+			Link:        "https://example.com/blog/hello",
+			Description: "First post",
+			Author:      "Adam Shkolnik",
+			Tags:        []string{"go"},
+			Published:   time.Date(2025, time.June, 1, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC),
+			Content:     "<p>Hi</p>",
+		},
+	},
+}
+
+func TestFeed_RSS(t *testing.T) {
+	body, err := testFeed.RSS()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title   string `xml:"title"`
+				GUID    string `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+				Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if doc.Version != "2.0" {
+		t.Errorf("expected version 2.0, got %q", doc.Version)
+	}
+	if doc.Channel.LastBuildDate != "Mon, 02 Jun 2025 10:00:00 +0000" {
+		t.Errorf("unexpected lastBuildDate %q", doc.Channel.LastBuildDate)
+	}
+	if len(doc.Channel.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(doc.Channel.Items))
+	}
+
+	item := doc.Channel.Items[0]
+	if item.Title != "Hello & Welcome" {
+		t.Errorf("expected title to round-trip, got %q", item.Title)
+	}
+	if item.GUID != "https://example.com/blog/post/1" {
+		t.Errorf("unexpected guid %q", item.GUID)
+	}
+	if item.PubDate != "Sun, 01 Jun 2025 10:00:00 +0000" {
+		t.Errorf("unexpected pubDate %q", item.PubDate)
+	}
+	if item.Creator != "Adam Shkolnik" {
+		t.Errorf("unexpected creator %q", item.Creator)
+	}
+	if item.Content != "<p>Hi</p>" {
+		t.Errorf("unexpected content %q", item.Content)
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	body, err := testFeed.Atom()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+			Summary   string `xml:"summary"`
+			Content   struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if doc.ID != "https://example.com/blog/feed.xml" || doc.Updated != "2025-06-02T10:00:00Z" {
+		t.Errorf("unexpected feed id %q or updated %q", doc.ID, doc.Updated)
+	}
+	if len(doc.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(doc.Entries))
+	}
+
+	entry := doc.Entries[0]
+	if entry.Published != "2025-06-01T10:00:00Z" || entry.Updated != "2025-06-02T10:00:00Z" {
+		t.Errorf("unexpected entry dates %q / %q", entry.Published, entry.Updated)
+	}
+	if entry.Summary != "First post" {
+		t.Errorf("unexpected summary %q", entry.Summary)
+	}
+	if entry.Content.Type != "html" || entry.Content.Value != "<p>Hi</p>" {
+		t.Errorf("unexpected content %+v", entry.Content)
+	}
+	if len(entry.Categories) != 1 || entry.Categories[0].Term != "go" {
+		t.Errorf("unexpected categories %+v", entry.Categories)
+	}
+}
+
+func TestFeed_AtomWithoutItems(t *testing.T) {
+	empty := Feed{Title: "Blog", Link: "https://example.com/blog/posts", FeedURL: "https://example.com/blog/atom.xml"}
+
+	before := time.Now().Add(-time.Second)
+	body, err := empty.Atom()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc struct {
+		Updated string `xml:"updated"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	updated, err := time.Parse(time.RFC3339, doc.Updated)
+	if err != nil || updated.Before(before.Truncate(time.Second)) {
+		t.Errorf("expected the current time as updated, got %q", doc.Updated)
+	}
+}
+
+func TestFeed_SummaryOnly(t *testing.T) {
+	summary := testFeed
+	summary.Items = []Item{testFeed.Items[0]}
+	summary.Items[0].Content = ""
+
+	body, err := summary.RSS()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(string(body), "content:encoded") {
+		t.Errorf("expected no content element, got %s", body)
+	}
+}
@@ -0,0 +1,63 @@
+package feed
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFeed_JSON(t *testing.T) {
+	body, err := testFeed.JSON()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if doc["version"] != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("unexpected version %v", doc["version"])
+	}
+	if doc["feed_url"] != "https://example.com/blog/feed.xml" {
+		t.Errorf("unexpected feed_url %v", doc["feed_url"])
+	}
+
+	items := doc["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+
+	item := items[0].(map[string]any)
+	if item["id"] != "https://example.com/blog/post/1" || item["content_html"] != "<p>Hi</p>" {
+		t.Errorf("unexpected item %v", item)
+	}
+	if item["date_published"] != "2025-06-01T10:00:00Z" {
+		t.Errorf("unexpected date_published %v", item["date_published"])
+	}
+	if _, ok := item["content_text"]; ok {
+		t.Errorf("expected no content_text alongside content_html, got %v", item["content_text"])
+	}
+}
+
+func TestFeed_JSONSummaryOnly(t *testing.T) {
+	summary := testFeed
+	summary.Items = []Item{testFeed.Items[0]}
+	summary.Items[0].Content = ""
+
+	body, err := summary.JSON()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if doc.Items[0]["content_text"] != "First post" {
+		t.Errorf("expected description as content_text, got %v", doc.Items[0]["content_text"])
+	}
+}
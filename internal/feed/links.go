@@ -0,0 +1,93 @@
+package feed
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// linkAttributes are the HTML attributes holding URLs that feed readers need to be absolute
+var linkAttributes = map[string]bool{"href": true, "src": true, "poster": true, "srcset": true}
+
+// AbsoluteLinks resolves the relative links in HTML content against base, the absolute URL of the page the content
+// is shown on, the way a browser would. Feed readers show content away from the site, where relative links break.
+func AbsoluteLinks(content, base string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil || !baseURL.IsAbs() {
+		return content
+	}
+
+	resolve := func(ref string) string {
+		parsed, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || parsed.IsAbs() {
+			return ref
+		}
+		return baseURL.ResolveReference(parsed).String()
+	}
+
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(content))
+
+	var b strings.Builder
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			return b.String()
+		}
+
+		// Everything not rewritten is copied byte for byte
+		raw := string(tokenizer.Raw())
+		if tokenType != nethtml.StartTagToken && tokenType != nethtml.SelfClosingTagToken {
+			b.WriteString(raw)
+			continue
+		}
+
+		token := tokenizer.Token()
+		changed := false
+		for i, attr := range token.Attr {
+			if !linkAttributes[attr.Key] {
+				continue
+			}
+
+			var value string
+			if attr.Key == "srcset" {
+				value = resolveSrcset(attr.Val, resolve)
+			} else {
+				value = resolve(attr.Val)
+			}
+
+			if value != attr.Val {
+				token.Attr[i].Val = value
+				changed = true
+			}
+		}
+
+		if !changed {
+			b.WriteString(raw)
+			continue
+		}
+
+		b.WriteString("<" + token.Data)
+		for _, attr := range token.Attr {
+			b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+		}
+		if tokenType == nethtml.SelfClosingTagToken {
+			b.WriteString(" /")
+		}
+		b.WriteString(">")
+	}
+}
+
+func resolveSrcset(srcset string, resolve func(string) string) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = resolve(fields[0])
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
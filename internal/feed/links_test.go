@@ -0,0 +1,47 @@
+package feed
+
+import "testing"
+
+func TestAbsoluteLinks(t *testing.T) {
+	base := "https://example.com/blog/hello"
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			"root-relative links",
+			`<p><a href="/blog/other">Other</a><img src="/media/1/photo.jpg" alt="Photo"></p>`,
+			`<p><a href="https://example.com/blog/other">Other</a><img src="https://example.com/media/1/photo.jpg" alt="Photo"></p>`,
+		},
+		{
+			"page-relative links",
+			`<a href="notes.txt?v=2#top">Notes</a>`,
+			`<a href="https://example.com/blog/notes.txt?v=2#top">Notes</a>`,
+		},
+		{
+			"srcset candidates",
+			`<img srcset="/media/1/photo-320w.jpg 320w,/media/1/photo.jpg 640w">`,
+			`<img srcset="https://example.com/media/1/photo-320w.jpg 320w, https://example.com/media/1/photo.jpg 640w">`,
+		},
+		{
+			"absolute links are kept",
+			`<a href="https://other.example/x">X</a> <a href="mailto:me@example.com">Mail</a>`,
+			`<a href="https://other.example/x">X</a> <a href="mailto:me@example.com">Mail</a>`,
+		},
+		{
+			"other markup is copied as it is",
+			`<p class='intro'>A &amp; B</p><!-- note -->`,
+			`<p class='intro'>A &amp; B</p><!-- note -->`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AbsoluteLinks(tt.content, base); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
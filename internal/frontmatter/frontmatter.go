@@ -0,0 +1,67 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata describes a post through the front matter block at the top of its file
+type Metadata struct {
+	Title       string    `yaml:"title" toml:"title"`
+	Description string    `yaml:"description" toml:"description"`
+	Author      string    `yaml:"author" toml:"author"`
+	Tags        []string  `yaml:"tags" toml:"tags"`
+	Date        time.Time `yaml:"date" toml:"date"`
+	Slug        string    `yaml:"slug" toml:"slug"`
+	Draft       bool      `yaml:"draft" toml:"draft"`
+}
+
+// Parse separates a leading front matter block from the document body.
+// YAML blocks are delimited by "---" lines and TOML blocks by "+++" lines.
+// Sources without front matter are returned unchanged with empty metadata.
+func Parse(source string) (Metadata, string, error) {
+	var meta Metadata
+
+	source = strings.TrimPrefix(source, "\ufeff")
+
+	delimiter := firstLine(source)
+	if delimiter != "---" && delimiter != "+++" {
+		return meta, source, nil
+	}
+
+	rest := source[strings.Index(source, "\n")+1:]
+	offset := 0
+	for _, line := range strings.SplitAfter(rest, "\n") {
+		if strings.TrimRight(line, "\r\n") == delimiter {
+			block := rest[:offset]
+
+			var err error
+			if delimiter == "---" {
+				err = yaml.Unmarshal([]byte(block), &meta)
+			} else {
+				_, err = toml.Decode(block, &meta)
+			}
+			if err != nil {
+				return meta, "", fmt.Errorf("failed to parse front matter: %w", err)
+			}
+
+			return meta, rest[offset+len(line):], nil
+		}
+		offset += len(line)
+	}
+
+	return meta, "", fmt.Errorf("unterminated front matter block")
+}
+
+// firstLine returns the first line of source without its line ending, or "" if there is no line break
+func firstLine(source string) string {
+	i := strings.Index(source, "\n")
+	if i == -1 {
+		return ""
+	}
+	return strings.TrimRight(source[:i], "\r")
+}
@@ -0,0 +1,99 @@
+package frontmatter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("yaml front matter", func(t *testing.T) {
+		source := "---\ntitle: Hello\ndescription: First post\nauthor: Jane Doe\ntags: [go, web]\ndate: 2024-05-01\nslug: hello-world\ndraft: true\n---\n<p>Body</p>\n"
+
+		meta, body, err := Parse(source)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if meta.Title != "Hello" {
+			t.Errorf("expected Title 'Hello', got %q", meta.Title)
+		}
+		if meta.Description != "First post" {
+			t.Errorf("expected Description 'First post', got %q", meta.Description)
+		}
+		if meta.Author != "Jane Doe" {
+			t.Errorf("expected Author 'Jane Doe', got %q", meta.Author)
+		}
+		if len(meta.Tags) != 2 || meta.Tags[0] != "go" || meta.Tags[1] != "web" {
+			t.Errorf("expected Tags [go web], got %v", meta.Tags)
+		}
+		if !meta.Date.Equal(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected Date 2024-05-01, got %v", meta.Date)
+		}
+		if meta.Slug != "hello-world" {
+			t.Errorf("expected Slug 'hello-world', got %q", meta.Slug)
+		}
+		if !meta.Draft {
+			t.Error("expected Draft to be true")
+		}
+		if body != "<p>Body</p>\n" {
+			t.Errorf("expected body without front matter, got %q", body)
+		}
+	})
+
+	t.Run("toml front matter", func(t *testing.T) {
+		source := "+++\ntitle = \"Hello\"\ntags = [\"go\"]\ndate = 2024-05-01T10:00:00Z\ndraft = false\n+++\n# Body\n"
+
+		meta, body, err := Parse(source)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if meta.Title != "Hello" {
+			t.Errorf("expected Title 'Hello', got %q", meta.Title)
+		}
+		if len(meta.Tags) != 1 || meta.Tags[0] != "go" {
+			t.Errorf("expected Tags [go], got %v", meta.Tags)
+		}
+		if !meta.Date.Equal(time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected Date 2024-05-01T10:00:00Z, got %v", meta.Date)
+		}
+		if meta.Draft {
+			t.Error("expected Draft to be false")
+		}
+		if body != "# Body\n" {
+			t.Errorf("expected body without front matter, got %q", body)
+		}
+	})
+
+	t.Run("no front matter", func(t *testing.T) {
+		source := "<h1>Plain</h1>\n"
+
+		meta, body, err := Parse(source)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if meta.Title != "" {
+			t.Errorf("expected empty Title, got %q", meta.Title)
+		}
+		if body != source {
+			t.Errorf("expected body unchanged, got %q", body)
+		}
+	})
+
+	t.Run("unterminated block", func(t *testing.T) {
+		_, _, err := Parse("+++\ntitle = \"Broken\"\n")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, _, err := Parse("---\ntitle: [unclosed\n---\nbody")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"website/internal/posts"
+)
+
+// apiPost is the public JSON representation of a post.
+// Storage details such as the content filename and lifecycle status are left out.
+type apiPost struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	Created     time.Time `json:"created"`
+	Edited      time.Time `json:"edited"`
+	PublishedAt time.Time `json:"published_at"`
+	ContentHTML string    `json:"content_html,omitempty"`
+}
+
+// apiPagination mirrors posts.PaginationInfo with links to the neighbouring pages
+type apiPagination struct {
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+	TotalPosts int    `json:"total_posts"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+type apiPostList struct {
+	Posts      []apiPost     `json:"posts"`
+	Pagination apiPagination `json:"pagination"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (env Env) APIPostsHandler(w http.ResponseWriter, r *http.Request) {
+	page, ok := pageParam(r)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "invalid page parameter")
+		return
+	}
+
+	list, paginationInfo, err := env.PostsRepository.GetPostsPaginated(r.Context(), page, r.URL.Query().Get("cursor"))
+	if errors.Is(err, posts.ErrInvalidCursor) {
+		writeAPIError(w, http.StatusBadRequest, "invalid cursor parameter")
+		return
+	}
+	if err != nil {
+		log.Println("failed to fetch paginated posts:", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to fetch posts")
+		return
+	}
+
+	// Unlike the HTML pages, out-of-range pages are an error rather than a redirect
+	if paginationInfo.TotalPages > 0 && page > paginationInfo.TotalPages {
+		writeAPIError(w, http.StatusNotFound, "page out of range")
+		return
+	}
+
+	siteURL := env.siteURL(r)
+	response := apiPostList{
+		Posts: make([]apiPost, 0, len(list)),
+		Pagination: apiPagination{
+			Page:       paginationInfo.CurrentPage,
+			PerPage:    posts.PostsPerPage,
+			TotalPages: paginationInfo.TotalPages,
+			TotalPosts: paginationInfo.TotalPosts,
+			HasNext:    paginationInfo.HasNext,
+			HasPrev:    paginationInfo.HasPrev,
+		},
+	}
+	if paginationInfo.HasNext {
+		response.Pagination.Next = siteURL + apiPageURL(paginationInfo.NextPage, paginationInfo.NextCursor)
+	}
+	if paginationInfo.HasPrev {
+		response.Pagination.Prev = siteURL + apiPageURL(paginationInfo.PrevPage, paginationInfo.PrevCursor)
+	}
+
+	for _, post := range list {
+		response.Posts = append(response.Posts, newAPIPost(post, siteURL))
+	}
+
+	// Pages also change when posts elsewhere are added or removed, so only the ETag validates them
+	env.writeAPIResponse(w, r, response, time.Time{})
+}
+
+// Helper function to build the path of a page of the posts API, with the cursor that fetches it by keyset
+func apiPageURL(page int, cursor string) string {
+	path := "/api/v1/posts?page=" + strconv.Itoa(page)
+	if cursor != "" {
+		path += "&cursor=" + url.QueryEscape(cursor)
+	}
+	return path
+}
+
+func (env Env) APIPostHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to fetch post %d: %v", id, err)
+		writeAPIError(w, http.StatusNotFound, "post not found")
+		return
+	}
+
+	// Drafts, scheduled and archived posts are not part of the public API
+	if !post.IsVisibleAt(time.Now()) {
+		writeAPIError(w, http.StatusNotFound, "post not found")
+		return
+	}
+
+	response := newAPIPost(*post, env.siteURL(r))
+
+	response.ContentHTML, err = env.postContent(r.Context(), *post)
+	if err != nil {
+		log.Printf("failed to load content for post %d (file: %s): %v", post.ID, post.Body, err)
+		writeAPIError(w, http.StatusNotFound, "post content not available")
+		return
+	}
+
+	env.writeAPIResponse(w, r, response, lastModified(*post))
+}
+
+// Helper function to convert a post to its public JSON representation
+func newAPIPost(post posts.Post, siteURL string) apiPost {
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return apiPost{
+		ID:          post.ID,
+		Title:       post.Title,
+		Slug:        post.Slug,
+		URL:         siteURL + post.Path(),
+		Author:      post.Author,
+		Description: post.Description,
+		Tags:        tags,
+		Created:     post.Created,
+		Edited:      post.Edited,
+		PublishedAt: post.PublishAt,
+	}
+}
+
+// Helper function to encode an API response and serve it with cache validators
+func (env Env) writeAPIResponse(w http.ResponseWriter, r *http.Request, v any, modified time.Time) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Println("failed to encode API response:", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+
+	env.serveCacheable(w, r, "application/json; charset=utf-8", body, modified)
+}
+
+// Helper function to write a JSON error body with the given status
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiError{Error: message})
+}
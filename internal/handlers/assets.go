@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"website/internal/content"
+	"website/internal/images"
+)
+
+// MediaCacheControl lets browsers and proxies reuse media for a day; assets can be replaced under the same name,
+// so they are revalidated with their ETag rather than cached forever
+const MediaCacheControl = "public, max-age=86400"
+
+// maxAssetUpload bounds a whole upload request, which may carry several assets
+const maxAssetUpload = 5 * content.MaxAssetSize
+
+// errInvalidImage is returned when an uploaded image can't be decoded
+var errInvalidImage = errors.New("invalid image")
+
+// adminAsset describes an uploaded asset with the URL posts can reference it by
+type adminAsset struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Modified    time.Time `json:"modified"`
+}
+
+func (env Env) MediaHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+
+	asset, err := env.ContentService.GetAsset(r.Context(), name)
+	if err != nil {
+		// Invalid names can't refer to an asset, so they are reported the same way as missing ones
+		if !errors.Is(err, content.ErrAssetNotFound) && content.ValidateAssetName(name) == nil {
+			log.Printf("failed to load asset %s: %v", name, err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", content.AssetContentType(name))
+	w.Header().Set("ETag", contentETag(asset.Data))
+	w.Header().Set("Cache-Control", MediaCacheControl)
+
+	// Uploaded files are served from the site's origin, so never let them run scripts or be sniffed as HTML
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+
+	http.ServeContent(w, r, "", asset.Modified, bytes.NewReader(asset.Data))
+}
+
+func (env Env) AdminListAssetsHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	assets, err := env.ContentService.ListAssets(r.Context(), content.PostAssetPrefix(id))
+	if err != nil {
+		log.Printf("failed to list assets of post %d: %v", id, err)
+		http.Error(w, "Failed to list assets", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]adminAsset, 0, len(assets))
+	for _, asset := range assets {
+		// Generated image variants are managed along with their originals
+		if images.IsVariant(asset.Name) {
+			continue
+		}
+		response = append(response, newAdminAsset(asset))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (env Env) AdminUploadAssetsHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := env.PostsRepository.GetPost(r.Context(), id); err != nil {
+		log.Printf("failed to get post %d: %v", id, err)
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAssetUpload)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		http.Error(w, "At least one file is required", http.StatusBadRequest)
+		return
+	}
+
+	uploaded := make([]adminAsset, 0, len(files))
+	for _, header := range files {
+		name := content.PostAssetPrefix(id) + content.CleanAssetFilename(header.Filename)
+		if err := content.ValidateAssetName(name); err != nil {
+			http.Error(w, "Unsupported file: "+header.Filename, http.StatusBadRequest)
+			return
+		}
+
+		if header.Size > content.MaxAssetSize {
+			http.Error(w, "File too large: "+header.Filename, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			http.Error(w, "Failed to read file content", http.StatusInternalServerError)
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "Failed to read file content", http.StatusInternalServerError)
+			return
+		}
+
+		size, err := env.saveAsset(r.Context(), name, data)
+		if errors.Is(err, errInvalidImage) {
+			log.Printf("failed to save asset %s: %v", name, err)
+			http.Error(w, "Invalid image: "+header.Filename, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to save asset %s: %v", name, err)
+			http.Error(w, "Failed to save asset", http.StatusInternalServerError)
+			return
+		}
+
+		uploaded = append(uploaded, newAdminAsset(content.Asset{Name: name, Size: size, Modified: time.Now()}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(uploaded)
+}
+
+func (env Env) AdminDeleteAssetHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	name := content.PostAssetPrefix(id) + r.PathValue("name")
+	if err := content.ValidateAssetName(name); err != nil {
+		http.Error(w, "Invalid asset name", http.StatusBadRequest)
+		return
+	}
+
+	env.deleteImageVariants(r.Context(), name)
+
+	if err := env.ContentService.DeleteAsset(r.Context(), name); err != nil {
+		log.Printf("failed to delete asset %s: %v", name, err)
+		http.Error(w, "Failed to delete asset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// Helper function to describe an asset for the admin dashboard
+func newAdminAsset(asset content.Asset) adminAsset {
+	return adminAsset{
+		Name:        asset.Name,
+		URL:         "/media/" + asset.Name,
+		ContentType: content.AssetContentType(asset.Name),
+		Size:        asset.Size,
+		Modified:    asset.Modified,
+	}
+}
+
+// Helper function to remove every asset uploaded for a post. Failures are only logged.
+func (env Env) deletePostAssets(ctx context.Context, postID int) {
+	assets, err := env.ContentService.ListAssets(ctx, content.PostAssetPrefix(postID))
+	if err != nil {
+		log.Printf("failed to list assets of post %d: %v", postID, err)
+		return
+	}
+
+	for _, asset := range assets {
+		if err := env.ContentService.DeleteAsset(ctx, asset.Name); err != nil {
+			log.Printf("failed to delete asset %s: %v", asset.Name, err)
+		}
+	}
+}
+
+// Helper function to store an asset, processing images into their resized variants first.
+// It returns the size of the stored asset.
+func (env Env) saveAsset(ctx context.Context, name string, data []byte) (int64, error) {
+	// Variants of a replaced image may have different widths, so clear out the old set first
+	env.deleteImageVariants(ctx, name)
+
+	var variants []images.File
+	if images.CanProcess(name) {
+		result, err := images.Process(name, data)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", errInvalidImage, err)
+		}
+		data, variants = result.Original.Data, result.Variants
+	}
+
+	if err := env.ContentService.SaveAsset(ctx, name, data); err != nil {
+		return 0, err
+	}
+
+	for _, variant := range variants {
+		if err := env.ContentService.SaveAsset(ctx, variant.Name, variant.Data); err != nil {
+			return 0, fmt.Errorf("failed to save image variant %s: %w", variant.Name, err)
+		}
+	}
+
+	return int64(len(data)), nil
+}
+
+// Helper function to load the variants manifest of a processed image
+func (env Env) imageManifest(ctx context.Context, name string) (images.Manifest, bool) {
+	if !images.CanProcess(name) || images.IsVariant(name) || content.ValidateAssetName(name) != nil {
+		return images.Manifest{}, false
+	}
+
+	asset, err := env.ContentService.GetAsset(ctx, images.ManifestName(name))
+	if err != nil {
+		// Images uploaded before processing existed have no manifest
+		if !errors.Is(err, content.ErrAssetNotFound) {
+			log.Printf("failed to load image manifest of %s: %v", name, err)
+		}
+		return images.Manifest{}, false
+	}
+
+	var manifest images.Manifest
+	if err := json.Unmarshal(asset.Data, &manifest); err != nil {
+		log.Printf("failed to decode image manifest of %s: %v", name, err)
+		return images.Manifest{}, false
+	}
+
+	return manifest, true
+}
+
+// Helper function to remove the generated variants and manifest of an image. Failures are only logged.
+func (env Env) deleteImageVariants(ctx context.Context, name string) {
+	manifest, ok := env.imageManifest(ctx, name)
+	if !ok {
+		return
+	}
+
+	for _, variant := range manifest.Variants {
+		for _, variantName := range []string{variant.Name, variant.WebP} {
+			if variantName == "" || variantName == name {
+				continue
+			}
+			if err := env.ContentService.DeleteAsset(ctx, variantName); err != nil {
+				log.Printf("failed to delete image variant %s: %v", variantName, err)
+			}
+		}
+	}
+
+	if err := env.ContentService.DeleteAsset(ctx, images.ManifestName(name)); err != nil {
+		log.Printf("failed to delete image manifest of %s: %v", name, err)
+	}
+}
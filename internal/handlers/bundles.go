@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"website/internal/posts"
+)
+
+// Helper function to delete the content file a post used to point at once no other post shares it.
+// Posts stored before content keys existed may share an uploaded filename.
+func (env Env) deleteReplacedContent(ctx context.Context, post posts.Post, previous string) {
+	if previous == "" || previous == post.Body {
+		return
+	}
+
+	shared, err := env.contentInUse(ctx, posts.Post{ID: post.ID, Body: previous})
+	if err != nil {
+		log.Printf("failed to check whether %s is still in use: %v", previous, err)
+		return
+	}
+	if shared {
+		return
+	}
+
+	if err := env.ContentService.DeleteContent(ctx, previous); err != nil {
+		log.Printf("failed to delete replaced content of post %d (file: %s): %v", post.ID, previous, err)
+	}
+}
+
+// Helper function to pick the status for a failed upload; bundles with undecodable images are the client's fault
+func bundleErrorStatus(err error) int {
+	if errors.Is(err, errInvalidImage) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"website/internal/cache"
+)
+
+// statsReporter is implemented by the caching decorators around the content service and posts repository
+type statsReporter interface {
+	Stats() cache.Stats
+}
+
+// cacheStats is one cache's counters along with its hit rate
+type cacheStats struct {
+	cache.Stats
+	HitRate float64 `json:"hitRate"`
+}
+
+// AdminCacheStatsHandler reports hit and miss counts for the caches that are turned on
+func (env Env) AdminCacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	stats := map[string]cacheStats{}
+	if reporter, ok := env.ContentService.(statsReporter); ok {
+		s := reporter.Stats()
+		stats["content"] = cacheStats{Stats: s, HitRate: s.HitRate()}
+	}
+
+	if reporter, ok := env.PostsRepository.(statsReporter); ok {
+		s := reporter.Stats()
+		stats["posts"] = cacheStats{Stats: s, HitRate: s.HitRate()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
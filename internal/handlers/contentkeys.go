@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"website/internal/content"
+)
+
+// MigrateContent moves posts stored under their uploaded filename to per-post content keys, and content
+// stored before it was addressed by hash into blobs. Each post's file is copied to its key before the post
+// is pointed at it, and old files are only deleted once no post refers to them, so an interrupted migration
+// can simply be run again. It reads every post's content, so it is run by the migrate command rather than at
+// startup; old files are still readable until then.
+func (env Env) MigrateContent(ctx context.Context) error {
+	live, err := env.PostsRepository.GetPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	deleted, err := env.PostsRepository.GetDeletedPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	all := append(live, deleted...)
+
+	// Uploads could reuse a filename, so several posts may have been sharing one file
+	oldFiles := map[string]bool{}
+	migrated := 0
+
+	for i, post := range all {
+		if post.Body == "" {
+			continue
+		}
+
+		hash, err := env.ContentService.ContentHash(ctx, post.Body)
+		if err != nil {
+			log.Printf("failed to check content of post %d (file: %s): %v", post.ID, post.Body, err)
+			continue
+		}
+
+		// Posts already at a key of their own only need content stored before hashing moved into a blob
+		key := post.Body
+		if !content.IsPostContentKey(post.ID, post.Body) {
+			key = content.PostContentKey(post.ID, post.Body)
+		} else if hash != "" {
+			// A run interrupted before deleting old files left the file the post was copied from behind
+			if post.Filename != "" && env.isLegacyFile(ctx, post.Filename) {
+				oldFiles[post.Filename] = true
+			}
+			continue
+		}
+
+		// Copying stores the content as a blob, which also covers content that is already at its key
+		if err := env.ContentService.CopyContent(ctx, post.Body, key); err != nil {
+			log.Printf("failed to copy content of post %d (file: %s) to %s: %v", post.ID, post.Body, key, err)
+			continue
+		}
+
+		migrated++
+		if post.Body == key {
+			continue
+		}
+
+		filename := post.Filename
+		if filename == "" {
+			filename = path.Base(post.Body)
+		}
+
+		if err := env.PostsRepository.UpdatePostBody(ctx, post.ID, key, filename); err != nil {
+			return fmt.Errorf("failed to update content key of post %d: %w", post.ID, err)
+		}
+
+		oldFiles[post.Body] = true
+		all[i].Body = key
+	}
+
+	if migrated == 0 && len(oldFiles) == 0 {
+		return nil
+	}
+
+	// Files that failed to copy are still referenced and are kept
+	for _, post := range all {
+		delete(oldFiles, post.Body)
+	}
+
+	for filename := range oldFiles {
+		if err := env.ContentService.DeleteContent(ctx, filename); err != nil {
+			log.Printf("failed to delete migrated content file %s: %v", filename, err)
+		}
+	}
+
+	log.Printf("migrated content of %d posts", migrated)
+	return nil
+}
+
+// Helper function to check whether a file is content stored under its uploaded filename before keys and
+// hashing, which is never a reference to a blob
+func (env Env) isLegacyFile(ctx context.Context, filename string) bool {
+	hash, err := env.ContentService.ContentHash(ctx, filename)
+	return err == nil && hash == ""
+}
+
+// CollectContentGarbage removes stored content that no post or revision refers to any more,
+// such as the content of purged posts and replaced uploads
+func (env Env) CollectContentGarbage(ctx context.Context) error {
+	removed, err := env.ContentService.CollectGarbage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect unreferenced content: %w", err)
+	}
+
+	if removed > 0 {
+		log.Printf("removed %d unreferenced content blobs", removed)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"website/internal/content"
+	"website/internal/posts"
+)
+
+// memoryRepository is a Repository holding posts in a map, for the handlers' storage tests
+type memoryRepository struct {
+	posts.Repository
+	posts map[int]posts.Post
+}
+
+func (r *memoryRepository) GetPost(ctx context.Context, id int) (*posts.Post, error) {
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, errors.New("post not found")
+	}
+	return &post, nil
+}
+
+func (r *memoryRepository) GetPosts(ctx context.Context) ([]posts.Post, error) {
+	var list []posts.Post
+	for _, post := range r.posts {
+		if post.DeletedAt == nil {
+			list = append(list, post)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryRepository) GetDeletedPosts(ctx context.Context) ([]posts.Post, error) {
+	var list []posts.Post
+	for _, post := range r.posts {
+		if post.DeletedAt != nil {
+			list = append(list, post)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryRepository) UpdatePostBody(ctx context.Context, id int, body, filename string) error {
+	post, ok := r.posts[id]
+	if !ok {
+		return errors.New("post not found")
+	}
+	post.Body, post.Filename = body, filename
+	r.posts[id] = post
+	return nil
+}
+
+// failingDeletes is a filesystem ContentService whose deletes fail, as if the process stopped before them
+type failingDeletes struct {
+	*content.FilesystemService
+}
+
+func (failingDeletes) DeleteContent(ctx context.Context, filename string) error {
+	return errors.New("interrupted")
+}
+
+// Helper function to build posts directory holding one file two posts were uploaded under, the second in the trash
+func newLegacyPosts(t *testing.T) (string, *memoryRepository) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "shared.html"), []byte("<p>Shared</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := time.Now()
+	repo := &memoryRepository{posts: map[int]posts.Post{
+		1: {ID: 1, Title: "First", Body: "shared.html"},
+		2: {ID: 2, Title: "Second", Body: "shared.html", DeletedAt: &deleted},
+	}}
+	return dir, repo
+}
+
+// Helper function to check that both posts moved to keys of their own and the shared file is gone
+func checkMigrated(t *testing.T, dir string, store content.ContentService, repo *memoryRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	for id, post := range repo.posts {
+		if post.Body != content.PostContentKey(id, "shared.html") || post.Filename != "shared.html" {
+			t.Errorf("expected post %d at its own key, got %q (%q)", id, post.Body, post.Filename)
+		}
+
+		got, err := store.GetContent(ctx, post.Body)
+		if err != nil || got != "<p>Shared</p>" {
+			t.Errorf("expected post %d's content at its key, got %q (%v)", id, got, err)
+		}
+		if hash, _ := store.ContentHash(ctx, post.Body); hash == "" {
+			t.Errorf("expected post %d's content to be stored as a blob", id)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "shared.html")); !os.IsNotExist(err) {
+		t.Errorf("expected the shared file to be deleted, got %v", err)
+	}
+}
+
+func TestMigrateContent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("posts sharing a file each get a copy", func(t *testing.T) {
+		dir, repo := newLegacyPosts(t)
+		store := content.NewFilesystemService(dir)
+		env := Env{PostsRepository: repo, ContentService: store}
+
+		if err := env.MigrateContent(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkMigrated(t, dir, store, repo)
+
+		// Running it again finds nothing to do
+		if err := env.MigrateContent(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkMigrated(t, dir, store, repo)
+	})
+
+	t.Run("run interrupted between copy and delete", func(t *testing.T) {
+		dir, repo := newLegacyPosts(t)
+		store := content.NewFilesystemService(dir)
+
+		interrupted := Env{PostsRepository: repo, ContentService: failingDeletes{store}}
+		if err := interrupted.MigrateContent(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "shared.html")); err != nil {
+			t.Fatalf("expected the shared file to be left behind, got %v", err)
+		}
+
+		env := Env{PostsRepository: repo, ContentService: store}
+		if err := env.MigrateContent(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkMigrated(t, dir, store, repo)
+	})
+}
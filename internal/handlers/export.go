@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+	"website/internal/posts"
+)
+
+// feedRoutes are the public documents that are not HTML pages but belong in a static copy of the site
+var feedRoutes = []string{"/blog/feed.xml", "/blog/atom.xml", "/blog/feed.json", "/sitemap.xml", "/robots.txt"}
+
+// ExportRoutes lists every public GET route a static copy of the site needs: the static pages, each page of the
+// post list and of every tag, each public post under both its numeric and its slug URL, and the feeds. Search
+// and the contact form need the server, so they are left out; media is found from the links on the pages.
+func (env Env) ExportRoutes(ctx context.Context) ([]string, error) {
+	// The root redirects, so it is exported as a page sending readers on
+	routes := append([]string{"/"}, staticRoutes...)
+
+	_, pagination, err := env.PostsRepository.GetPostsPaginated(ctx, 1, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count post list pages: %w", err)
+	}
+	// The first page of the post list is one of the static routes
+	routes = append(routes, laterPages("/blog/posts", pagination.TotalPages)...)
+
+	tags, err := env.PostsRepository.GetTagCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	routes = append(routes, "/blog/tags")
+	for _, tag := range tags {
+		pages := posts.NewPaginationInfo(tag.Count, 1).TotalPages
+		basePath := "/blog/tags/" + url.PathEscape(tag.Name)
+		routes = append(routes, basePath)
+		routes = append(routes, laterPages(basePath, pages)...)
+	}
+
+	all, err := env.PostsRepository.GetPosts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	now := time.Now()
+	for _, post := range all {
+		if !post.IsVisibleAt(now) {
+			continue
+		}
+
+		// Posts with a slug redirect from their numeric URL, which is kept for old links
+		routes = append(routes, "/blog/post/"+strconv.Itoa(post.ID))
+		if post.Slug != "" {
+			routes = append(routes, post.Path())
+		}
+	}
+
+	return append(routes, feedRoutes...), nil
+}
+
+// Helper function to list the pages of a paginated list after the first
+func laterPages(basePath string, pages int) []string {
+	var routes []string
+	for page := 2; page <= pages; page++ {
+		routes = append(routes, basePath+"?page="+strconv.Itoa(page))
+	}
+	return routes
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"website/internal/feed"
+	"website/internal/posts"
+)
+
+// FeedItems is the number of most recent posts included in feeds
+const FeedItems = 20
+
+func (env Env) RSSHandler(w http.ResponseWriter, r *http.Request) {
+	env.serveFeed(w, r, "/blog/feed.xml", "application/rss+xml; charset=utf-8", feed.Feed.RSS)
+}
+
+func (env Env) AtomHandler(w http.ResponseWriter, r *http.Request) {
+	env.serveFeed(w, r, "/blog/atom.xml", "application/atom+xml; charset=utf-8", feed.Feed.Atom)
+}
+
+func (env Env) JSONFeedHandler(w http.ResponseWriter, r *http.Request) {
+	env.serveFeed(w, r, "/blog/feed.json", "application/feed+json; charset=utf-8", feed.Feed.JSON)
+}
+
+// Helper function to render a feed of the latest posts in one of the supported formats
+func (env Env) serveFeed(w http.ResponseWriter, r *http.Request, path, contentType string, render func(feed.Feed) ([]byte, error)) {
+	f, err := env.buildFeed(r, path)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to build feed:", err)
+		return
+	}
+
+	body, err := render(f)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to render feed:", err)
+		return
+	}
+
+	env.serveCacheable(w, r, contentType, body, f.Updated)
+}
+
+// Helper function to serve a generated response with an ETag and Last-Modified time,
+// answering conditional requests with 304 Not Modified. A zero modified time omits Last-Modified.
+func (env Env) serveCacheable(w http.ResponseWriter, r *http.Request, contentType string, body []byte, modified time.Time) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", contentETag(body))
+
+	// Without SITE_URL absolute links come from the request's Host header, which a client can forge,
+	// so shared caches must not keep the response and hand it to everyone else
+	if env.Config.SiteURL != "" {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	} else {
+		w.Header().Set("Cache-Control", "private, max-age=300")
+	}
+
+	// ServeContent checks If-None-Match and If-Modified-Since against the headers set above
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+// Helper function to build the feed of the most recent public posts
+func (env Env) buildFeed(r *http.Request, path string) (feed.Feed, error) {
+	all, err := env.PostsRepository.GetPosts(r.Context())
+	if err != nil {
+		return feed.Feed{}, err
+	}
+
+	siteURL := env.siteURL(r)
+	f := feed.Feed{
+		Title:       "Adam Shkolnik",
+		Description: "Latest thoughts and updates from Adam Shkolnik",
+		Link:        siteURL + "/blog/posts",
+		FeedURL:     siteURL + path,
+		Author:      "Adam Shkolnik",
+	}
+
+	now := time.Now()
+	for _, post := range all {
+		if len(f.Items) == FeedItems {
+			break
+		}
+		if !post.IsVisibleAt(now) {
+			continue
+		}
+
+		item := feed.Item{
+			ID:          siteURL + "/blog/post/" + strconv.Itoa(post.ID),
+			Title:       post.Title,
+			Link:        siteURL + post.Path(),
+			Description: post.Description,
+			Author:      post.Author,
+			Tags:        post.Tags,
+			Published:   post.Created,
+			Updated:     post.Edited,
+		}
+
+		if env.Config.FeedFullContent {
+			item.Content, err = env.postContent(r.Context(), post)
+			if err != nil {
+				// Fall back to the description rather than dropping the post from the feed
+				log.Printf("failed to load content for feed item %d (file: %s): %v", post.ID, post.Body, err)
+			}
+			item.Content = feed.AbsoluteLinks(item.Content, item.Link)
+		}
+
+		f.Items = append(f.Items, item)
+
+		// A scheduled post changes the feed when it goes live, not when it was last edited
+		if modified := lastModified(post); modified.After(f.Updated) {
+			f.Updated = modified
+		}
+	}
+
+	return f, nil
+}
+
+// Helper function to get when a post last changed from a reader's point of view
+func lastModified(post posts.Post) time.Time {
+	if post.PublishAt.After(post.Edited) {
+		return post.PublishAt
+	}
+	return post.Edited
+}
+
+// Helper function to build a strong ETag from a response body
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Helper function to get the absolute base URL of the site, preferring the configured SITE_URL
+func (env Env) siteURL(r *http.Request) string {
+	if env.Config.SiteURL != "" {
+		return env.Config.SiteURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -4,59 +4,106 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/resend/resend-go/v2"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
 	"net/mail"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
+	"website/internal/bundle"
+	"website/internal/content"
+	"website/internal/frontmatter"
+	"website/internal/images"
+	"website/internal/markdown"
 	"website/internal/posts"
+	"website/internal/search"
 )
 
 func (env Env) PostsHandler(w http.ResponseWriter, r *http.Request) {
-	type Data struct {
-		Posts      []posts.Post
-		Pagination posts.PaginationInfo
-		Active     string
-	}
-
 	log.Println(r.Header.Get("Accept"))
 
-	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
-
 	// Parse page parameter from query string
-	pageStr := r.URL.Query().Get("page")
-	page := 1 // Default to page 1
-	
-	if pageStr != "" {
-		parsedPage, err := strconv.Atoi(pageStr)
-		if err != nil || parsedPage < 1 {
-			// Invalid page parameter, redirect to page 1
-			http.Redirect(w, r, "/blog/posts?page=1", http.StatusSeeOther)
-			return
-		}
-		page = parsedPage
+	page, ok := pageParam(r)
+	if !ok {
+		// Invalid page parameter, redirect to page 1
+		http.Redirect(w, r, "/blog/posts?page=1", http.StatusSeeOther)
+		return
 	}
 
-	// Get paginated posts
-	list, paginationInfo, err := env.PostsRepository.GetPostsPaginated(page)
+	// Get paginated posts; Previous and Next links carry a cursor so they are fetched by keyset
+	list, paginationInfo, err := env.PostsRepository.GetPostsPaginated(r.Context(), page, r.URL.Query().Get("cursor"))
 
+	if errors.Is(err, posts.ErrInvalidCursor) {
+		// Stale or mangled cursor, fall back to the page number
+		http.Redirect(w, r, fmt.Sprintf("/blog/posts?page=%d", page), http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("failed to fetch paginated posts:", err)
 		return
 	}
 
-	// If page is out of bounds and we have posts, redirect to last page
-	if paginationInfo.TotalPages > 0 && page > paginationInfo.TotalPages {
-		http.Redirect(w, r, fmt.Sprintf("/blog/posts?page=%d", paginationInfo.TotalPages), http.StatusSeeOther)
+	env.renderPostList(w, r, page, postList{
+		Posts:      list,
+		Pagination: paginationInfo,
+		BasePath:   "/blog/posts",
+	})
+}
+
+func (env Env) TagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("tag")
+	basePath := "/blog/tags/" + url.PathEscape(tag)
+
+	// Parse page parameter from query string
+	page, ok := pageParam(r)
+	if !ok {
+		http.Redirect(w, r, basePath+"?page=1", http.StatusSeeOther)
+		return
+	}
+
+	list, paginationInfo, err := env.PostsRepository.GetPostsByTagPaginated(r.Context(), tag, page)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to fetch posts tagged %s: %v", tag, err)
+		return
+	}
+
+	if paginationInfo.TotalPosts == 0 {
+		http.Error(w, "Tag not found", http.StatusNotFound)
 		return
 	}
 
-	err = env.Templates["posts.html"].ExecuteTemplate(w, "posts.html", Data{list, paginationInfo, "posts"})
+	env.renderPostList(w, r, page, postList{
+		Posts:      list,
+		Pagination: paginationInfo,
+		BasePath:   basePath,
+		Tag:        tag,
+	})
+}
+
+func (env Env) TagsHandler(w http.ResponseWriter, r *http.Request) {
+	type Data struct {
+		Tags   []posts.TagCount
+		Active string
+	}
+
+	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
+
+	tags, err := env.PostsRepository.GetTagCounts(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to fetch tag counts:", err)
+		return
+	}
+
+	err = env.Templates["tags.html"].ExecuteTemplate(w, "tags.html", Data{tags, "posts"})
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -65,15 +112,65 @@ func (env Env) PostsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (env Env) PostHandler(w http.ResponseWriter, r *http.Request) {
+func (env Env) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	type Result struct {
+		posts.Post
+		Snippet template.HTML
+	}
+
 	type Data struct {
-		Post    posts.Post
-		Content template.HTML
-		Active  string
+		Query      string
+		Results    []Result
+		Pagination posts.PaginationInfo
+		BasePath   string
+		Active     string
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	basePath := "/blog/search?q=" + url.QueryEscape(query)
+
+	// Parse page parameter from query string
+	page, ok := pageParam(r)
+	if !ok {
+		http.Redirect(w, r, basePath+"&page=1", http.StatusSeeOther)
+		return
+	}
+
+	data := Data{Query: query, BasePath: basePath, Active: "posts"}
+
+	// An empty query just shows the search form
+	if query != "" {
+		results, paginationInfo, err := env.PostsRepository.SearchPosts(r.Context(), query, page)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("failed to search posts for %q: %v", query, err)
+			return
+		}
+
+		// If page is out of bounds and we have results, redirect to last page
+		if paginationInfo.TotalPages > 0 && page > paginationInfo.TotalPages {
+			http.Redirect(w, r, fmt.Sprintf("%s&page=%d", basePath, paginationInfo.TotalPages), http.StatusSeeOther)
+			return
+		}
+
+		data.Pagination = paginationInfo
+		for _, result := range results {
+			data.Results = append(data.Results, Result{result.Post, search.Highlight(result.Snippet)})
+		}
 	}
 
 	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
 
+	err := env.Templates["search.html"].ExecuteTemplate(w, "search.html", data)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to execute template:", err)
+		return
+	}
+}
+
+func (env Env) PostHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract post ID from URL path
 	idStr := r.PathValue("id")
 	if idStr == "" {
@@ -87,33 +184,45 @@ func (env Env) PostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := env.PostsRepository.GetPost(id)
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
 	if err != nil {
 		log.Printf("failed to fetch post %d: %v", id, err)
 		http.Error(w, "Post not found", http.StatusNotFound)
 		return
 	}
 
-	// Load HTML content for the post
-	htmlContent, err := env.ContentService.GetContent(post.Body)
-	if err != nil {
-		log.Printf("failed to load content for post %d (file: %s): %v", id, post.Body, err)
-		http.Error(w, "Post content not available", http.StatusNotFound)
+	// Drafts, archived and not-yet-due scheduled posts are not public
+	if !post.IsVisibleAt(time.Now()) {
+		http.Error(w, "Post not found", http.StatusNotFound)
 		return
 	}
 
-	data := Data{
-		Post:    *post,
-		Content: template.HTML(htmlContent),
-		Active:  "posts",
+	// Numeric URLs are kept for old links; posts with a slug live at /blog/{slug}
+	if post.Slug != "" {
+		http.Redirect(w, r, post.Path(), http.StatusMovedPermanently)
+		return
 	}
 
-	err = env.Templates["post.html"].ExecuteTemplate(w, "post.html", data)
+	env.renderPost(r.Context(), w, post)
+}
+
+func (env Env) PostBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	post, err := env.PostsRepository.GetPostBySlug(r.Context(), slug)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println("failed to execute template:", err)
+		log.Printf("failed to fetch post %s: %v", slug, err)
+		http.Error(w, "Post not found", http.StatusNotFound)
 		return
 	}
+
+	// Drafts, archived and not-yet-due scheduled posts are not public
+	if !post.IsVisibleAt(time.Now()) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	env.renderPost(r.Context(), w, post)
 }
 
 func (env Env) AboutHandler(w http.ResponseWriter, r *http.Request) {
@@ -193,15 +302,26 @@ func (env Env) AdminDashboardHandler(w http.ResponseWriter, r *http.Request) {
 		FirebaseAPIKey string
 		ProjectID      string
 		Posts          []posts.Post
+		Trash          []posts.Post
+		TrashRetention time.Duration
+		RetentionDays  int
+		Statuses       []posts.Status
+		Now            time.Time
 	}
 
 	// Get posts for dashboard
-	postsList, err := env.PostsRepository.GetPosts()
+	postsList, err := env.PostsRepository.GetPosts(r.Context())
 	if err != nil {
 		log.Printf("failed to fetch posts for dashboard: %v", err)
 		postsList = []posts.Post{} // Empty slice if error
 	}
 
+	trash, err := env.PostsRepository.GetDeletedPosts(r.Context())
+	if err != nil {
+		log.Printf("failed to fetch deleted posts for dashboard: %v", err)
+		trash = []posts.Post{}
+	}
+
 	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
 
 	data := Data{
@@ -209,6 +329,11 @@ func (env Env) AdminDashboardHandler(w http.ResponseWriter, r *http.Request) {
 		FirebaseAPIKey: env.Config.FirebaseWebAPIKey,
 		ProjectID:      env.Config.ProjectID,
 		Posts:          postsList,
+		Trash:          trash,
+		TrashRetention: env.Config.TrashRetention,
+		RetentionDays:  int(env.Config.TrashRetention.Hours() / 24),
+		Statuses:       posts.Statuses,
+		Now:            time.Now(),
 	}
 
 	err = env.Templates["admin-dashboard.html"].ExecuteTemplate(w, "admin-dashboard.html", data)
@@ -346,8 +471,8 @@ func (env Env) AdminDeletePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete the post
-	err = env.PostsRepository.DeletePost(id)
+	// Move the post to the trash; it is purged for good later
+	err = env.PostsRepository.DeletePost(r.Context(), id)
 	if err != nil {
 		log.Printf("failed to delete post %d: %v", id, err)
 		http.Error(w, "Failed to delete post", http.StatusInternalServerError)
@@ -380,9 +505,11 @@ func (env Env) AdminUpdatePostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Parse JSON body
 	var updateData struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Body        string `json:"body"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Slug        string   `json:"slug"`
+		Tags        []string `json:"tags"`
+		Trusted     *bool    `json:"trusted"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
@@ -396,24 +523,132 @@ func (env Env) AdminUpdatePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If body is empty, get the original body to keep it unchanged
-	if updateData.Body == "" {
-		post, err := env.PostsRepository.GetPost(id)
-		if err != nil {
-			http.Error(w, "Failed to get original post", http.StatusInternalServerError)
-			return
-		}
-		updateData.Body = post.Body
+	// Start from the original post so fields not in the request stay unchanged
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get original post", http.StatusInternalServerError)
+		return
+	}
+	original := *post
+
+	post.Title = updateData.Title
+	post.Description = updateData.Description
+
+	// The content key is assigned by the server when a file is uploaded, so the body is never taken from the request
+
+	// If slug is empty, keep the original slug so existing links stay valid
+	if updateData.Slug != "" {
+		post.Slug = updateData.Slug
+	}
+
+	// Tags are replaced only when the request includes them
+	if updateData.Tags != nil {
+		post.Tags = posts.NormalizeTags(updateData.Tags)
+	}
+
+	// Trusting a post skips sanitization from its next upload on; content already cleaned stays as it is
+	if updateData.Trusted != nil {
+		post.Trusted = *updateData.Trusted
+	}
+
+	if err := env.assignSlug(r.Context(), post); err != nil {
+		log.Printf("failed to assign slug for post %d: %v", id, err)
+		http.Error(w, "Failed to update post", http.StatusInternalServerError)
+		return
+	}
+
+	if err := env.ensureRevisionBaseline(r.Context(), original); err != nil {
+		log.Printf("failed to save revision of post %d before updating: %v", id, err)
+		http.Error(w, "Failed to update post", http.StatusInternalServerError)
+		return
 	}
 
 	// Update the post
-	err = env.PostsRepository.UpdatePost(id, updateData.Title, updateData.Description, updateData.Body)
+	err = env.PostsRepository.UpdatePost(r.Context(), *post)
 	if err != nil {
 		log.Printf("failed to update post %d: %v", id, err)
 		http.Error(w, "Failed to update post", http.StatusInternalServerError)
 		return
 	}
 
+	env.indexPost(r.Context(), *post)
+	env.recordRevision(r.Context(), *post, "Updated details")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+func (env Env) AdminUpdatePostStatusHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	// Extract post ID from URL path
+	idStr := r.PathValue("id")
+	if idStr == "" {
+		http.Error(w, "Post ID is required", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse JSON body
+	var statusData struct {
+		Status    string    `json:"status"`
+		PublishAt time.Time `json:"publishAt"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&statusData); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	status, err := posts.ParseStatus(statusData.Status)
+	if err != nil {
+		http.Error(w, "Invalid post status", http.StatusBadRequest)
+		return
+	}
+
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get post %d: %v", id, err)
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	// Keep the existing publish time unless a new one is given
+	now := time.Now()
+	publishAt := post.PublishAt
+	if !statusData.PublishAt.IsZero() {
+		publishAt = statusData.PublishAt
+	}
+
+	switch status {
+	case posts.StatusScheduled:
+		if !publishAt.After(now) {
+			http.Error(w, "Scheduled posts need a future publish time", http.StatusBadRequest)
+			return
+		}
+	case posts.StatusPublished:
+		// Publishing goes live immediately unless backdated
+		if publishAt.IsZero() || publishAt.After(now) {
+			publishAt = now
+		}
+	}
+
+	err = env.PostsRepository.UpdatePostStatus(r.Context(), id, status, publishAt)
+	if err != nil {
+		log.Printf("failed to update status of post %d: %v", id, err)
+		http.Error(w, "Failed to update post status", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
@@ -439,7 +674,7 @@ func (env Env) AdminGetPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the post
-	post, err := env.PostsRepository.GetPost(id)
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
 	if err != nil {
 		log.Printf("failed to get post %d: %v", id, err)
 		http.Error(w, "Post not found", http.StatusNotFound)
@@ -457,7 +692,7 @@ func (env Env) AdminListPostsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get all posts
-	posts, err := env.PostsRepository.GetPosts()
+	posts, err := env.PostsRepository.GetPosts(r.Context())
 	if err != nil {
 		log.Printf("failed to get posts: %v", err)
 		http.Error(w, "Failed to get posts", http.StatusInternalServerError)
@@ -482,82 +717,413 @@ func (env Env) AdminUploadPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get form values
-	title := strings.TrimSpace(r.FormValue("title"))
-	excerpt := strings.TrimSpace(r.FormValue("excerpt"))
+	form := uploadForm{
+		Title:   strings.TrimSpace(r.FormValue("title")),
+		Excerpt: strings.TrimSpace(r.FormValue("excerpt")),
+		Slug:    strings.TrimSpace(r.FormValue("slug")),
+		Tags:    posts.ParseTags(r.FormValue("tags")),
+	}
 	editMode := r.FormValue("editMode")
 	postIdStr := r.FormValue("postId")
 
-	// Validate required fields
-	if title == "" {
-		http.Error(w, "Title is required", http.StatusBadRequest)
-		return
+	// The trusted checkbox is only sent by forms that show it; edits without it keep the post's setting
+	if trustedStr := r.FormValue("trusted"); trustedStr != "" {
+		trusted := trustedStr == "true"
+		form.Trusted = &trusted
+	}
+
+	if statusStr := r.FormValue("status"); statusStr != "" {
+		form.Status, err = posts.ParseStatus(statusStr)
+		if err != nil {
+			http.Error(w, "Invalid post status", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if publishAtStr := r.FormValue("publishAt"); publishAtStr != "" {
+		form.PublishAt, err = time.Parse(time.RFC3339, publishAtStr)
+		if err != nil {
+			http.Error(w, "Invalid publish time", http.StatusBadRequest)
+			return
+		}
 	}
 
 	// Handle file upload
 	file, header, err := r.FormFile("htmlFile")
 	if err != nil {
-		http.Error(w, "HTML file is required", http.StatusBadRequest)
+		http.Error(w, "HTML or Markdown file is required", http.StatusBadRequest)
 		return
 	}
 	defer file.Close()
 
+	// Zip bundles carry the post file along with the assets it links to
+	var postBundle *bundle.Bundle
+	filename := header.Filename
+	var content []byte
+
+	if bundle.IsBundle(header.Filename) {
+		if header.Size > bundle.MaxSize {
+			http.Error(w, "Bundle too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, "Failed to read file content", http.StatusInternalServerError)
+			return
+		}
+
+		b, err := bundle.Read(data)
+		if err != nil {
+			log.Printf("failed to read bundle %s: %v", header.Filename, err)
+			http.Error(w, "Invalid bundle: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		postBundle = &b
+		filename = b.Document.Name
+		content = b.Document.Data
+	} else {
+		// Read file content
+		content = make([]byte, header.Size)
+		_, err = file.Read(content)
+		if err != nil {
+			http.Error(w, "Failed to read file content", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Validate file type
-	if !strings.HasSuffix(header.Filename, ".html") {
-		http.Error(w, "Only HTML files are allowed", http.StatusBadRequest)
+	isMarkdown := markdown.IsMarkdown(filename)
+	if !strings.HasSuffix(filename, ".html") && !isMarkdown {
+		http.Error(w, "Only HTML and Markdown files are allowed", http.StatusBadRequest)
 		return
 	}
 
-	// Read file content
-	content := make([]byte, header.Size)
-	_, err = file.Read(content)
+	// Front matter describes the post; form fields override it
+	meta, body, err := frontmatter.Parse(string(content))
 	if err != nil {
-		http.Error(w, "Failed to read file content", http.StatusInternalServerError)
+		log.Printf("failed to parse front matter in %s: %v", header.Filename, err)
+		http.Error(w, "Invalid front matter", http.StatusBadRequest)
 		return
 	}
 
-	// Store the file using the content service
-	bodyFilename := header.Filename
-	err = env.ContentService.SaveContent(bodyFilename, string(content))
-	if err != nil {
-		log.Printf("failed to save content file %s: %v", bodyFilename, err)
-		http.Error(w, "Failed to save file content", http.StatusInternalServerError)
+	// Validate required fields; edited posts keep their existing title
+	if form.Title == "" && meta.Title == "" && editMode != "true" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
 		return
 	}
 
+	// Markdown keeps its front matter and is rendered on read, HTML is stored without it
+	if !isMarkdown {
+		content = []byte(body)
+	}
+
+	// Edits keep the post as it was before the upload can overwrite its content file
+	var post *posts.Post
 	if editMode == "true" && postIdStr != "" {
-		// Handle edit mode
 		postId, err := strconv.Atoi(postIdStr)
 		if err != nil {
 			http.Error(w, "Invalid post ID", http.StatusBadRequest)
 			return
 		}
 
-		// Update existing post
-		err = env.PostsRepository.UpdatePost(postId, title, excerpt, bodyFilename)
+		post, err = env.PostsRepository.GetPost(r.Context(), postId)
+		if err != nil {
+			log.Printf("failed to get post %d: %v", postId, err)
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+
+		if err := env.ensureRevisionBaseline(r.Context(), *post); err != nil {
+			log.Printf("failed to save revision of post %d before updating: %v", postId, err)
+			http.Error(w, "Failed to update post", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Untrusted uploads can't run scripts on the public site, so clean them before they are stored
+	var sanitized []string
+	if !form.trusted(post) {
+		cleaned, report, err := env.sanitizeUpload(filename, string(content))
 		if err != nil {
-			log.Printf("failed to update post %d: %v", postId, err)
+			log.Printf("failed to sanitize %s: %v", header.Filename, err)
+			http.Error(w, "Invalid post content", http.StatusBadRequest)
+			return
+		}
+		content, sanitized = []byte(cleaned), report
+	}
+
+	if post != nil {
+		// Handle edit mode
+		postId := post.ID
+
+		applyFrontMatter(post, meta)
+		form.apply(post)
+		post.Filename = filename
+		post.Sanitized = sanitized
+		post.ApplyLifecycleDefaults(time.Now())
+
+		if err := env.assignSlug(r.Context(), post); err != nil {
+			log.Printf("failed to assign slug for post %d: %v", postId, err)
 			http.Error(w, "Failed to update post", http.StatusInternalServerError)
 			return
 		}
 
+		// The new file is staged under a key of its own, so the post keeps its old content if the update fails
+		if err := env.updatePostWithContent(r.Context(), post, filename, string(content), postBundle); err != nil {
+			log.Printf("failed to update post %d with %s: %v", postId, header.Filename, err)
+			http.Error(w, "Failed to update post", bundleErrorStatus(err))
+			return
+		}
+
+		env.indexPost(r.Context(), *post)
+		env.recordRevision(r.Context(), *post, "Uploaded "+header.Filename)
+
 		// Redirect back to dashboard
 		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 	} else {
 		// Handle new post creation
 		// For now, use a default author - you could get this from the authenticated user
-		author := "Adam Shkolnik"
-		err = env.PostsRepository.CreatePost(title, excerpt, bodyFilename, author)
-		if err != nil {
-			log.Printf("failed to create new post: %v", err)
+		post := &posts.Post{Author: "Adam Shkolnik"}
+
+		applyFrontMatter(post, meta)
+		form.apply(post)
+		post.Filename = filename
+		post.Sanitized = sanitized
+		post.ApplyLifecycleDefaults(time.Now())
+
+		if err := env.assignSlug(r.Context(), post); err != nil {
+			log.Printf("failed to assign slug for new post: %v", err)
 			http.Error(w, "Failed to create post", http.StatusInternalServerError)
 			return
 		}
 
+		// The content is stored under the post's reserved ID before the post is created pointing at it
+		if err := env.createPostWithContent(r.Context(), post, filename, string(content), postBundle); err != nil {
+			log.Printf("failed to create new post from %s: %v", header.Filename, err)
+			http.Error(w, "Failed to create post", bundleErrorStatus(err))
+			return
+		}
+
+		env.indexPost(r.Context(), *post)
+		env.recordRevision(r.Context(), *post, "Created")
+
 		// Redirect back to dashboard
 		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 	}
 }
 
+// Helper function to map front matter metadata onto a post, leaving fields it does not set unchanged
+func applyFrontMatter(post *posts.Post, meta frontmatter.Metadata) {
+	if meta.Title != "" {
+		post.Title = meta.Title
+	}
+	if meta.Description != "" {
+		post.Description = meta.Description
+	}
+	if meta.Author != "" {
+		post.Author = meta.Author
+	}
+	if !meta.Date.IsZero() {
+		post.PublishAt = meta.Date
+
+		// Backdated posts keep their original creation date
+		if meta.Date.Before(time.Now()) {
+			post.Created = meta.Date
+		}
+	}
+	if meta.Slug != "" {
+		post.Slug = meta.Slug
+	}
+	if meta.Draft {
+		post.Status = posts.StatusDraft
+	}
+	if len(meta.Tags) > 0 {
+		post.Tags = posts.NormalizeTags(meta.Tags)
+	}
+}
+
+// uploadForm holds the metadata fields submitted alongside an uploaded post file
+type uploadForm struct {
+	Title     string
+	Excerpt   string
+	Slug      string
+	Status    posts.Status
+	PublishAt time.Time
+	Tags      []string
+	Trusted   *bool
+}
+
+// Helper method to report whether an upload skips sanitization: as set by the form, or else as the edited post is
+func (form uploadForm) trusted(post *posts.Post) bool {
+	if form.Trusted != nil {
+		return *form.Trusted
+	}
+	return post != nil && post.Trusted
+}
+
+// Helper method to apply upload form fields, which take precedence over front matter
+func (form uploadForm) apply(post *posts.Post) {
+	if form.Title != "" {
+		post.Title = form.Title
+	}
+	if form.Excerpt != "" {
+		post.Description = form.Excerpt
+	}
+	if form.Slug != "" {
+		post.Slug = form.Slug
+	}
+	if form.Status != "" {
+		post.Status = form.Status
+	}
+	if !form.PublishAt.IsZero() {
+		post.PublishAt = form.PublishAt
+	}
+	if len(form.Tags) > 0 {
+		post.Tags = form.Tags
+	}
+	if form.Trusted != nil {
+		post.Trusted = *form.Trusted
+	}
+}
+
+// postList is the data behind a paginated list of posts, either every post or a single tag's archive
+type postList struct {
+	Posts      []posts.Post
+	Pagination posts.PaginationInfo
+	BasePath   string
+	Tag        string
+	Active     string
+}
+
+// Helper function to render a paginated post list, redirecting out-of-bounds pages to the last page
+func (env Env) renderPostList(w http.ResponseWriter, r *http.Request, page int, data postList) {
+	// If page is out of bounds and we have posts, redirect to last page
+	if data.Pagination.TotalPages > 0 && page > data.Pagination.TotalPages {
+		http.Redirect(w, r, fmt.Sprintf("%s?page=%d", data.BasePath, data.Pagination.TotalPages), http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
+
+	data.Active = "posts"
+
+	err := env.Templates["posts.html"].ExecuteTemplate(w, "posts.html", data)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to execute template:", err)
+		return
+	}
+}
+
+// Helper function to read the page query parameter, defaulting to 1; ok is false for invalid values
+func pageParam(r *http.Request) (page int, ok bool) {
+	pageStr := r.URL.Query().Get("page")
+	if pageStr == "" {
+		return 1, true
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		return 0, false
+	}
+
+	return page, true
+}
+
+// Helper function to render a single post page with its content
+func (env Env) renderPost(ctx context.Context, w http.ResponseWriter, post *posts.Post) {
+	type Data struct {
+		Post    posts.Post
+		Content template.HTML
+		Active  string
+	}
+
+	w.Header().Set("Content-Type", "text/html; text/css; application/javascript; charset=utf-8")
+
+	// Load HTML content for the post
+	htmlContent, err := env.postContent(ctx, *post)
+	if errors.Is(err, content.ErrCorruptContent) {
+		log.Printf("content of post %d (file: %s) is corrupt: %v", post.ID, post.Body, err)
+		http.Error(w, "Post content failed its integrity check", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to load content for post %d (file: %s): %v", post.ID, post.Body, err)
+		http.Error(w, "Post content not available", http.StatusNotFound)
+		return
+	}
+
+	// Point uploaded images at their resized variants
+	htmlContent = images.RewriteHTML(htmlContent, func(name string) (images.Manifest, bool) {
+		return env.imageManifest(ctx, name)
+	})
+
+	data := Data{
+		Post:    *post,
+		Content: template.HTML(htmlContent),
+		Active:  "posts",
+	}
+
+	err = env.Templates["post.html"].ExecuteTemplate(w, "post.html", data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to execute template:", err)
+		return
+	}
+}
+
+// ReindexPosts rebuilds the search index from every stored post and its content
+func (env Env) ReindexPosts(ctx context.Context) error {
+	list, err := env.PostsRepository.GetPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get posts to index: %w", err)
+	}
+
+	for _, post := range list {
+		env.indexPost(ctx, post)
+	}
+
+	log.Printf("indexed %d posts for search", len(list))
+	return nil
+}
+
+// Helper function to refresh the search index entry of a post from its stored content.
+// Failures are only logged since the post itself has already been saved.
+func (env Env) indexPost(ctx context.Context, post posts.Post) {
+	var text string
+
+	htmlContent, err := env.ContentService.GetContent(ctx, post.Body)
+	if err != nil {
+		// Still index the title and description so the post can be found
+		log.Printf("failed to load content to index post %d (file: %s): %v", post.ID, post.Body, err)
+	} else {
+		text = search.PlainText(htmlContent)
+	}
+
+	if err := env.PostsRepository.IndexPost(ctx, post, text); err != nil {
+		log.Printf("failed to index post %d: %v", post.ID, err)
+	}
+}
+
+// Helper function to give a post a normalized slug that no other post uses
+func (env Env) assignSlug(ctx context.Context, post *posts.Post) error {
+	base := posts.Slugify(post.Slug)
+	if base == "" {
+		base = posts.Slugify(post.Title)
+	}
+
+	slug, err := posts.UniqueSlug(ctx, env.PostsRepository, base, post.ID)
+	if err != nil {
+		return err
+	}
+
+	post.Slug = slug
+	return nil
+}
+
 // Helper function to verify admin authentication
 func (env Env) verifyAdminAuth(w http.ResponseWriter, r *http.Request) bool {
 	// Extract token from Authorization header
@@ -577,7 +1143,7 @@ func (env Env) verifyAdminAuth(w http.ResponseWriter, r *http.Request) bool {
 	idToken := parts[1]
 
 	// Verify the ID token with Firebase
-	_, err := env.FirebaseAuth.VerifyIDToken(context.Background(), idToken)
+	_, err := env.FirebaseAuth.VerifyIDToken(r.Context(), idToken)
 	if err != nil {
 		log.Printf("firebase token verification failed: %v", err)
 		http.Error(w, "Invalid token", http.StatusUnauthorized)
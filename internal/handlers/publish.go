@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"website/internal/bundle"
+	"website/internal/content"
+	"website/internal/markdown"
+	"website/internal/posts"
+)
+
+// stagedContent is new content stored for a post that the post does not point at yet,
+// along with what it takes to remove it again
+type stagedContent struct {
+	postID   int
+	key      string          // versioned key the content is stored under
+	previous string          // key the post pointed at before, which staging never touches
+	added    []string        // bundle assets that did not exist before
+	replaced []content.Asset // bundle assets that were overwritten, with their earlier data
+}
+
+// Helper function to create a post along with its uploaded content. An id is reserved first so the content can be
+// staged under the post's key, then the post is created pointing at it in a single transaction. If creating the post
+// fails, the staged content is removed again and nothing is left behind.
+func (env Env) createPostWithContent(ctx context.Context, post *posts.Post, filename, document string, b *bundle.Bundle) error {
+	id, err := env.PostsRepository.ReservePostID(ctx)
+	if err != nil {
+		return err
+	}
+
+	staged, err := env.stageContent(ctx, id, "", filename, document, b)
+	if err != nil {
+		return err
+	}
+
+	post.ID = id
+	post.Body = staged.key
+	if _, err := env.PostsRepository.CreatePost(ctx, *post); err != nil {
+		env.discardStaged(ctx, staged)
+		post.ID, post.Body = 0, ""
+		return err
+	}
+
+	return nil
+}
+
+// Helper function to replace a post's content and details together. The new content is staged under a key of its own,
+// so readers keep seeing the old content until the post is updated to point at the new key. If the update fails, the
+// staged content is removed again; once it succeeds, the content the post used to point at is deleted.
+func (env Env) updatePostWithContent(ctx context.Context, post *posts.Post, filename, document string, b *bundle.Bundle) error {
+	previous := post.Body
+
+	staged, err := env.stageContent(ctx, post.ID, previous, filename, document, b)
+	if err != nil {
+		return err
+	}
+
+	post.Body = staged.key
+	if err := env.PostsRepository.UpdatePost(ctx, *post); err != nil {
+		env.discardStaged(ctx, staged)
+		post.Body = previous
+		return err
+	}
+
+	// The revision baseline keeps a copy of the old file, so it can go once nothing points at it
+	env.deleteReplacedContent(ctx, *post, previous)
+	return nil
+}
+
+// Helper function to store a post's new content under a versioned key, saving a bundle's assets alongside the
+// post's other uploads first and pointing the document's relative links at them. Whatever was stored before
+// a failure is removed again.
+func (env Env) stageContent(ctx context.Context, postID int, previous, filename, document string, b *bundle.Bundle) (stagedContent, error) {
+	staged := stagedContent{postID: postID, previous: previous}
+
+	if b != nil {
+		prefix := content.PostAssetPrefix(postID)
+
+		for _, asset := range b.Assets {
+			name := prefix + asset.Name
+
+			// Keep what an asset held before so a failed upload can put it back
+			existing, err := env.ContentService.GetAsset(ctx, name)
+			if err != nil && !errors.Is(err, content.ErrAssetNotFound) {
+				env.discardStaged(ctx, staged)
+				return stagedContent{}, fmt.Errorf("failed to check %s: %w", asset.Path, err)
+			}
+
+			if _, err := env.saveAsset(ctx, name, asset.Data); err != nil {
+				env.discardStaged(ctx, staged)
+				return stagedContent{}, fmt.Errorf("failed to save %s: %w", asset.Path, err)
+			}
+
+			if existing.Data != nil {
+				staged.replaced = append(staged.replaced, existing)
+			} else {
+				staged.added = append(staged.added, name)
+			}
+		}
+
+		document = b.RewriteLinks(document, markdown.IsMarkdown(filename), "/media/"+prefix)
+	}
+
+	staged.key = content.VersionedContentKey(postID, filename, document)
+	if err := env.ContentService.SaveContent(ctx, staged.key, document); err != nil {
+		env.discardStaged(ctx, staged)
+		return stagedContent{}, fmt.Errorf("failed to save content file %s: %w", staged.key, err)
+	}
+
+	return staged, nil
+}
+
+// Helper function to remove staged content that no post ended up pointing at and put back the assets it replaced.
+// Cleanup runs even when the request was cancelled, since that may be why storing the post failed. Failures are only logged.
+func (env Env) discardStaged(ctx context.Context, staged stagedContent) {
+	ctx = context.WithoutCancel(ctx)
+
+	// Uploading the content the post already has stages it under the key the post points at
+	if staged.key != "" && staged.key != staged.previous {
+		if err := env.ContentService.DeleteContent(ctx, staged.key); err != nil {
+			log.Printf("failed to delete staged content of post %d (file: %s): %v", staged.postID, staged.key, err)
+		}
+	}
+
+	for _, name := range staged.added {
+		env.deleteImageVariants(ctx, name)
+		if err := env.ContentService.DeleteAsset(ctx, name); err != nil {
+			log.Printf("failed to delete staged asset %s: %v", name, err)
+		}
+	}
+
+	for _, asset := range staged.replaced {
+		if _, err := env.saveAsset(ctx, asset.Name, asset.Data); err != nil {
+			log.Printf("failed to restore asset %s: %v", asset.Name, err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+	"website/internal/bundle"
+	"website/internal/content"
+	"website/internal/posts"
+)
+
+// failingWrites is a Repository whose creates and updates fail, as if the database went away mid-request
+type failingWrites struct {
+	*memoryRepository
+}
+
+func (failingWrites) ReservePostID(ctx context.Context) (int, error) {
+	return 5, nil
+}
+
+func (failingWrites) CreatePost(ctx context.Context, post posts.Post) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func (failingWrites) UpdatePost(ctx context.Context, post posts.Post) error {
+	return errors.New("database unavailable")
+}
+
+// Helper function to build an Env over a posts directory and a repository holding post 3, which already has
+// content and a notes asset
+func newPublishEnv(t *testing.T) (Env, *content.FilesystemService) {
+	ctx := context.Background()
+	store := content.NewFilesystemService(t.TempDir())
+
+	if err := store.SaveContent(ctx, "3/post-old.html", "<p>Old</p>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveAsset(ctx, "3/notes.txt", []byte("old notes")); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := failingWrites{&memoryRepository{posts: map[int]posts.Post{
+		3: {ID: 3, Title: "Existing", Body: "3/post-old.html"},
+	}}}
+	return Env{PostsRepository: repo, ContentService: store}, store
+}
+
+// Helper function to check which assets are stored under a post's prefix, generated variants included
+func checkAssets(t *testing.T, store *content.FilesystemService, postID int, expected map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+
+	assets, err := store.ListAssets(ctx, content.PostAssetPrefix(postID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != len(expected) {
+		var names []string
+		for _, asset := range assets {
+			names = append(names, asset.Name)
+		}
+		t.Errorf("expected %d assets, got %v", len(expected), names)
+	}
+
+	for name, data := range expected {
+		asset, err := store.GetAsset(ctx, name)
+		if err != nil || string(asset.Data) != data {
+			t.Errorf("expected %s to hold %q, got %q (%v)", name, data, asset.Data, err)
+		}
+	}
+}
+
+func TestPublishRollback(t *testing.T) {
+	ctx := context.Background()
+	document := `<p><a href="notes.txt">Notes</a></p>`
+
+	t.Run("failed create discards staged content and assets", func(t *testing.T) {
+		env, store := newPublishEnv(t)
+		b := &bundle.Bundle{Assets: []bundle.File{
+			{Path: "notes.txt", Name: "notes.txt", Data: []byte("notes")},
+			{Path: "icon.png", Name: "icon.png", Data: encodeTestPNG(t)},
+		}}
+
+		post := posts.Post{Title: "New"}
+		if err := env.createPostWithContent(ctx, &post, "post.html", document, b); err == nil {
+			t.Fatal("expected the create to fail")
+		}
+
+		if post.ID != 0 || post.Body != "" {
+			t.Errorf("expected the post to be left without an id and content, got %d (%q)", post.ID, post.Body)
+		}
+		staged := content.VersionedContentKey(5, "post.html", b.RewriteLinks(document, false, "/media/5/"))
+		if _, err := store.GetContent(ctx, staged); err == nil {
+			t.Errorf("expected staged content %s to be deleted", staged)
+		}
+		checkAssets(t, store, 5, nil)
+	})
+
+	t.Run("failed update restores replaced content and assets", func(t *testing.T) {
+		env, store := newPublishEnv(t)
+		b := &bundle.Bundle{Assets: []bundle.File{
+			{Path: "notes.txt", Name: "notes.txt", Data: []byte("new notes")},
+			{Path: "extra.txt", Name: "extra.txt", Data: []byte("extra")},
+		}}
+
+		post := getPost(t, env, 3)
+		if err := env.updatePostWithContent(ctx, post, "post.html", document, b); err == nil {
+			t.Fatal("expected the update to fail")
+		}
+
+		if post.Body != "3/post-old.html" {
+			t.Errorf("expected the post to point at its old content, got %q", post.Body)
+		}
+		if got, err := store.GetContent(ctx, "3/post-old.html"); err != nil || got != "<p>Old</p>" {
+			t.Errorf("expected the old content to be kept, got %q (%v)", got, err)
+		}
+		staged := content.VersionedContentKey(3, "post.html", b.RewriteLinks(document, false, "/media/3/"))
+		if _, err := store.GetContent(ctx, staged); err == nil {
+			t.Errorf("expected staged content %s to be deleted", staged)
+		}
+		checkAssets(t, store, 3, map[string]string{"3/notes.txt": "old notes"})
+	})
+
+	t.Run("failed update of unchanged content keeps it", func(t *testing.T) {
+		env, store := newPublishEnv(t)
+		key := content.VersionedContentKey(3, "post.html", "<p>Same</p>")
+		if err := store.SaveContent(ctx, key, "<p>Same</p>"); err != nil {
+			t.Fatal(err)
+		}
+
+		post := getPost(t, env, 3)
+		post.Body = key
+		if err := env.updatePostWithContent(ctx, post, "post.html", "<p>Same</p>", nil); err == nil {
+			t.Fatal("expected the update to fail")
+		}
+
+		if got, err := store.GetContent(ctx, key); err != nil || got != "<p>Same</p>" {
+			t.Errorf("expected the content the post points at to be kept, got %q (%v)", got, err)
+		}
+	})
+
+	t.Run("failed staging removes what it stored", func(t *testing.T) {
+		env, store := newPublishEnv(t)
+		b := &bundle.Bundle{Assets: []bundle.File{
+			{Path: "notes.txt", Name: "notes.txt", Data: []byte("new notes")},
+			{Path: "extra.txt", Name: "extra.txt", Data: []byte("extra")},
+			{Path: "broken.png", Name: "broken.png", Data: []byte("not an image")},
+		}}
+
+		if _, err := env.stageContent(ctx, 3, "3/post-old.html", "post.html", document, b); !errors.Is(err, errInvalidImage) {
+			t.Fatalf("expected an invalid image error, got %v", err)
+		}
+
+		staged := content.VersionedContentKey(3, "post.html", b.RewriteLinks(document, false, "/media/3/"))
+		if _, err := store.GetContent(ctx, staged); err == nil {
+			t.Errorf("expected no content to be staged at %s", staged)
+		}
+		checkAssets(t, store, 3, map[string]string{"3/notes.txt": "old notes"})
+	})
+}
+
+// Helper function to get a post from the Env's repository, failing the test if it's missing
+func getPost(t *testing.T, env Env, id int) *posts.Post {
+	t.Helper()
+
+	post, err := env.PostsRepository.GetPost(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+// Helper function to encode an image large enough to get resized variants
+func encodeTestPNG(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 10))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"website/internal/content"
+	"website/internal/diff"
+	"website/internal/posts"
+)
+
+// maxDiffLines and maxDiffBytes cap the content of each revision compared, since a diff takes time proportional
+// to the length of the content times the number of changes
+const (
+	maxDiffLines = 5000
+	maxDiffBytes = 1 << 20
+)
+
+// revisionDiff compares two revisions of a post, field by field and as a unified diff of their content
+type revisionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Fields  []fieldChange `json:"fields"`
+	Content string        `json:"content"`
+}
+
+type fieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+func (env Env) AdminListRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := env.PostsRepository.GetRevisions(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get revisions of post %d: %v", id, err)
+		http.Error(w, "Failed to get revisions", http.StatusInternalServerError)
+		return
+	}
+
+	if revisions == nil {
+		revisions = []posts.Revision{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (env Env) AdminDiffRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	fromNumber, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	toNumber, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		http.Error(w, "from and to revision numbers are required", http.StatusBadRequest)
+		return
+	}
+
+	from, ok := env.lookupRevision(r.Context(), w, id, fromNumber)
+	if !ok {
+		return
+	}
+	to, ok := env.lookupRevision(r.Context(), w, id, toNumber)
+	if !ok {
+		return
+	}
+
+	fromContent, err := env.revisionContent(r.Context(), *from)
+	if err != nil {
+		log.Printf("failed to load content of revision %d of post %d: %v", from.Number, id, err)
+		http.Error(w, "Failed to load revision content", http.StatusInternalServerError)
+		return
+	}
+	toContent, err := env.revisionContent(r.Context(), *to)
+	if err != nil {
+		log.Printf("failed to load content of revision %d of post %d: %v", to.Number, id, err)
+		http.Error(w, "Failed to load revision content", http.StatusInternalServerError)
+		return
+	}
+
+	for _, text := range []string{fromContent, toContent} {
+		if len(text) > maxDiffBytes || strings.Count(text, "\n") > maxDiffLines {
+			http.Error(w, fmt.Sprintf("Revisions are too large to compare (limit %d lines or %d KB each)", maxDiffLines, maxDiffBytes>>10), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	response := revisionDiff{
+		From:   from.Number,
+		To:     to.Number,
+		Fields: []fieldChange{},
+		Content: diff.Unified(
+			fmt.Sprintf("revision %d (%s)", from.Number, from.Body),
+			fmt.Sprintf("revision %d (%s)", to.Number, to.Body),
+			fromContent, toContent),
+	}
+
+	fields := []fieldChange{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"slug", from.Slug, to.Slug},
+		{"tags", strings.Join(from.Tags, ", "), strings.Join(to.Tags, ", ")},
+		{"file", from.Body, to.Body},
+	}
+	for _, field := range fields {
+		if field.From != field.To {
+			response.Fields = append(response.Fields, field)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (env Env) AdminRestoreRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get post %d: %v", id, err)
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	rev, ok := env.lookupRevision(r.Context(), w, id, number)
+	if !ok {
+		return
+	}
+
+	// Load the versioned copy before changing anything; revisions without a copy leave the current content file in place
+	var source string
+	if rev.Content != "" {
+		source, err = env.ContentService.GetRevision(r.Context(), rev.Content)
+		if err != nil {
+			log.Printf("failed to load content of revision %d of post %d: %v", number, id, err)
+			http.Error(w, "Failed to load revision content", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	previousBody := post.Body
+	filename := rev.Body
+	rev.Apply(post)
+	post.Body = previousBody
+
+	// The old slug may have been taken by another post since
+	if err := env.assignSlug(r.Context(), post); err != nil {
+		log.Printf("failed to assign slug for post %d: %v", id, err)
+		http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+		return
+	}
+
+	// Stage the restored content under a versioned key so the post keeps its current content if the update fails
+	staged := stagedContent{postID: id, key: previousBody, previous: previousBody}
+	if rev.Content != "" {
+		staged.key = content.VersionedContentKey(id, filename, source)
+		if err := env.ContentService.SaveContent(r.Context(), staged.key, source); err != nil {
+			log.Printf("failed to restore content file %s: %v", staged.key, err)
+			http.Error(w, "Failed to restore content", http.StatusInternalServerError)
+			return
+		}
+	}
+	post.Body = staged.key
+
+	if err := env.PostsRepository.UpdatePost(r.Context(), *post); err != nil {
+		env.discardStaged(r.Context(), staged)
+		log.Printf("failed to update post %d: %v", id, err)
+		http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+		return
+	}
+
+	env.deleteReplacedContent(r.Context(), *post, previousBody)
+
+	env.indexPost(r.Context(), *post)
+	env.recordRevision(r.Context(), *post, fmt.Sprintf("Restored revision %d", number))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// Helper function to get a revision of a post, writing a 404 or 500 response when it cannot
+func (env Env) lookupRevision(ctx context.Context, w http.ResponseWriter, postID, number int) (*posts.Revision, bool) {
+	rev, err := env.PostsRepository.GetRevision(ctx, postID, number)
+	if errors.Is(err, posts.ErrRevisionNotFound) {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("failed to get revision %d of post %d: %v", number, postID, err)
+		http.Error(w, "Failed to get revision", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return rev, true
+}
+
+// Helper function to get the content source a revision was saved with.
+// Revisions whose content could not be copied have none and diff as empty.
+func (env Env) revisionContent(ctx context.Context, rev posts.Revision) (string, error) {
+	if rev.Content == "" {
+		return "", nil
+	}
+	return env.ContentService.GetRevision(ctx, rev.Content)
+}
+
+// Helper function to store the current state of a post as its next revision.
+// Failures are only logged since the post itself has already been saved.
+func (env Env) recordRevision(ctx context.Context, post posts.Post, note string) {
+	if _, err := env.saveRevision(ctx, post, note); err != nil {
+		log.Printf("failed to record revision of post %d: %v", post.ID, err)
+	}
+}
+
+// Helper function to keep the state of a post from before revision history existed,
+// so the first update after upgrading does not overwrite it
+func (env Env) ensureRevisionBaseline(ctx context.Context, post posts.Post) error {
+	revisions, err := env.PostsRepository.GetRevisions(ctx, post.ID)
+	if err != nil {
+		return err
+	}
+	if len(revisions) > 0 {
+		return nil
+	}
+
+	_, err = env.saveRevision(ctx, post, "Saved before first edit")
+	return err
+}
+
+// Helper function to copy a post's content file and store a revision pointing at the copy.
+// A post whose content cannot be copied still gets a revision of its other fields.
+func (env Env) saveRevision(ctx context.Context, post posts.Post, note string) (posts.Revision, error) {
+	// Content keys put each post's file in its own directory, but revision copies are flat
+	contentName := fmt.Sprintf("%d-%d-%s", post.ID, time.Now().UnixNano(), strings.ReplaceAll(post.Body, "/", "-"))
+
+	if err := env.ContentService.SaveRevision(ctx, post.Body, contentName); err != nil {
+		log.Printf("failed to copy content of post %d (file: %s) for its revision: %v", post.ID, post.Body, err)
+		contentName = ""
+	}
+
+	return env.PostsRepository.CreateRevision(ctx, posts.NewRevision(post, contentName, note))
+}
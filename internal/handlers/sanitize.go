@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"website/internal/markdown"
+	"website/internal/posts"
+	"website/internal/sanitize"
+)
+
+// Helper function to build the HTML sanitizer policy, extended with the configured allowlist
+func (env Env) sanitizePolicy() *sanitize.Policy {
+	policy := sanitize.DefaultPolicy()
+	policy.AllowElements(env.Config.SanitizeAllowElements...)
+	policy.AllowAttributes(env.Config.SanitizeAllowAttributes...)
+	return policy
+}
+
+// Helper function to sanitize an uploaded post file, returning the content to store and what was removed.
+// HTML is cleaned before it is stored. Markdown is stored as written, since its HTML only exists once
+// it is rendered, so it is only checked here and cleaned by postContent.
+func (env Env) sanitizeUpload(filename, document string) (string, []string, error) {
+	if !markdown.IsMarkdown(filename) {
+		cleaned, report := env.sanitizePolicy().Sanitize(document)
+		return cleaned, report.Strings(), nil
+	}
+
+	rendered, err := markdown.Render(document)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, report := env.sanitizePolicy().Sanitize(rendered.HTML)
+	return document, report.Strings(), nil
+}
+
+// Helper function to load the HTML of a post for the public site, feeds and API.
+// Untrusted Markdown posts are always sanitized here; HTML posts only when SANITIZE_ON_RENDER is set,
+// e.g. to cover files uploaded before sanitization existed.
+func (env Env) postContent(ctx context.Context, post posts.Post) (string, error) {
+	htmlContent, err := env.ContentService.GetContent(ctx, post.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if !post.Trusted && (env.Config.SanitizeOnRender || markdown.IsMarkdown(post.Body)) {
+		htmlContent, _ = env.sanitizePolicy().Sanitize(htmlContent)
+	}
+
+	return htmlContent, nil
+}
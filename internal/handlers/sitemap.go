@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"website/internal/sitemap"
+)
+
+// staticRoutes are the public pages registered in main.go that are not backed by posts. The root only redirects,
+// so it is left out and listed separately where it is needed.
+var staticRoutes = []string{"/about", "/contact", "/blog/posts"}
+
+func (env Env) SitemapHandler(w http.ResponseWriter, r *http.Request) {
+	all, err := env.PostsRepository.GetPosts(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to fetch posts for sitemap:", err)
+		return
+	}
+
+	siteURL := env.siteURL(r)
+
+	urls := make([]sitemap.URL, 0, len(staticRoutes)+len(all))
+	for _, route := range staticRoutes {
+		urls = append(urls, sitemap.URL{Loc: siteURL + route})
+	}
+
+	now := time.Now()
+	var modified time.Time
+	for _, post := range all {
+		if !post.IsVisibleAt(now) {
+			continue
+		}
+
+		urls = append(urls, sitemap.URL{Loc: siteURL + post.Path(), LastMod: post.Edited})
+		if m := lastModified(post); m.After(modified) {
+			modified = m
+		}
+	}
+
+	body, err := sitemap.Render(urls)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("failed to render sitemap:", err)
+		return
+	}
+
+	env.serveCacheable(w, r, "application/xml; charset=utf-8", body, modified)
+}
+
+func (env Env) RobotsHandler(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+
+	b.WriteString("User-agent: *\n")
+	b.WriteString("Disallow: /admin/\n")
+	for _, path := range env.Config.RobotsDisallow {
+		fmt.Fprintf(&b, "Disallow: %s\n", path)
+	}
+	fmt.Fprintf(&b, "\nSitemap: %s/sitemap.xml\n", env.siteURL(r))
+
+	env.serveCacheable(w, r, "text/plain; charset=utf-8", []byte(b.String()), time.Time{})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// BackfillSlugs gives every post stored before slugs existed a slug from its title, so its numeric URL
+// redirects to the slug URL like newer posts. Posts are stored as they were apart from the slug, so their
+// edit time is left alone, and trashed posts get a slug too so restoring one keeps it unique. Older posts
+// are handled first, so they get a title's plain slug before any later post with the same title.
+func (env Env) BackfillSlugs(ctx context.Context) (int, error) {
+	live, err := env.PostsRepository.GetPosts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	deleted, err := env.PostsRepository.GetDeletedPosts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	all := append(live, deleted...)
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	assigned := 0
+	for _, post := range all {
+		if post.Slug != "" {
+			continue
+		}
+
+		if err := env.assignSlug(ctx, &post); err != nil {
+			return assigned, fmt.Errorf("failed to choose a slug for post %d: %w", post.ID, err)
+		}
+		if err := env.PostsRepository.ImportPost(ctx, post); err != nil {
+			return assigned, fmt.Errorf("failed to save slug of post %d: %w", post.ID, err)
+		}
+		assigned++
+	}
+
+	if assigned > 0 {
+		log.Printf("assigned slugs to %d posts", assigned)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"website/internal/posts"
+)
+
+func (r *memoryRepository) GetPostBySlug(ctx context.Context, slug string) (*posts.Post, error) {
+	for _, post := range r.posts {
+		if post.Slug == slug {
+			return &post, nil
+		}
+	}
+	return nil, posts.ErrPostNotFound
+}
+
+func (r *memoryRepository) ImportPost(ctx context.Context, post posts.Post) error {
+	r.posts[post.ID] = post
+	return nil
+}
+
+func TestBackfillSlugs(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2020, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := &memoryRepository{posts: map[int]posts.Post{
+		1: {ID: 1, Title: "Hello, World!", Status: posts.StatusPublished, PublishAt: created, Created: created, Edited: created},
+		2: {ID: 2, Title: "Hello World", Status: posts.StatusPublished, PublishAt: created, Created: created, Edited: created},
+		3: {ID: 3, Title: "Kept", Slug: "my-own-slug", Status: posts.StatusPublished, PublishAt: created},
+	}}
+	env := Env{PostsRepository: repo}
+
+	assigned, err := env.BackfillSlugs(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if assigned != 2 {
+		t.Errorf("expected 2 slugs assigned, got %d", assigned)
+	}
+
+	if got := repo.posts[1].Slug; got != "hello-world" {
+		t.Errorf("expected the older post to get the plain slug, got %q", got)
+	}
+	if got := repo.posts[2].Slug; got != "hello-world-2" {
+		t.Errorf("expected the later post to get a numbered slug, got %q", got)
+	}
+	if got := repo.posts[3].Slug; got != "my-own-slug" {
+		t.Errorf("expected an existing slug to be kept, got %q", got)
+	}
+	if !repo.posts[1].Edited.Equal(created) {
+		t.Errorf("expected the edit time to be left alone, got %v", repo.posts[1].Edited)
+	}
+
+	// The old numeric URL now redirects to the slug URL
+	req := httptest.NewRequest(http.MethodGet, "/blog/post/1", nil)
+	req.SetPathValue("id", "1")
+	rec := httptest.NewRecorder()
+	env.PostHandler(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/blog/hello-world" {
+		t.Errorf("expected a redirect to /blog/hello-world, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Running it again has nothing left to do
+	if assigned, err := env.BackfillSlugs(ctx); err != nil || assigned != 0 {
+		t.Errorf("expected nothing assigned on a second run, got %d (%v)", assigned, err)
+	}
+}
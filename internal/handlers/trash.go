@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"website/internal/posts"
+)
+
+// TrashPurgeInterval is how often posts past the trash retention period are purged
+const TrashPurgeInterval = time.Hour
+
+func (env Env) AdminTrashHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	deleted, err := env.PostsRepository.GetDeletedPosts(r.Context())
+	if err != nil {
+		log.Printf("failed to get deleted posts: %v", err)
+		http.Error(w, "Failed to get deleted posts", http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == nil {
+		deleted = []posts.Post{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deleted)
+}
+
+func (env Env) AdminRestorePostHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := env.PostsRepository.RestorePost(r.Context(), id); err != nil {
+		log.Printf("failed to restore post %d: %v", id, err)
+		http.Error(w, "Failed to restore post", http.StatusInternalServerError)
+		return
+	}
+
+	// The Firestore search index drops posts moved to the trash
+	if post, err := env.PostsRepository.GetPost(r.Context(), id); err == nil {
+		env.indexPost(r.Context(), *post)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+func (env Env) AdminPurgePostHandler(w http.ResponseWriter, r *http.Request) {
+	// Verify authentication
+	if !env.verifyAdminAuth(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := env.PostsRepository.GetPost(r.Context(), id)
+	if err != nil || post.DeletedAt == nil {
+		http.Error(w, "Post not found in trash", http.StatusNotFound)
+		return
+	}
+
+	if err := env.purgePost(r.Context(), *post); err != nil {
+		log.Printf("failed to purge post %d: %v", id, err)
+		http.Error(w, "Failed to purge post", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// PurgeExpiredPosts permanently removes posts that have been in the trash longer than the configured retention
+func (env Env) PurgeExpiredPosts(ctx context.Context) error {
+	if env.Config.TrashRetention <= 0 {
+		return nil
+	}
+
+	deleted, err := env.PostsRepository.GetDeletedPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	cutoff := time.Now().Add(-env.Config.TrashRetention)
+	purged := 0
+	for _, post := range deleted {
+		if post.DeletedAt.After(cutoff) {
+			continue
+		}
+
+		// Keep going so one broken post does not hold up the rest of the trash
+		if err := env.purgePost(ctx, post); err != nil {
+			log.Printf("failed to purge expired post %d: %v", post.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		log.Printf("purged %d posts from the trash", purged)
+	}
+	return nil
+}
+
+// Helper function to permanently remove a post in the trash along with its content file, revision copies and assets.
+// Files are removed only after the post is gone, and failures there are only logged.
+func (env Env) purgePost(ctx context.Context, post posts.Post) error {
+	// Revisions are removed with the post, so read them first to know which copies to delete
+	revisions, err := env.PostsRepository.GetRevisions(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	shared, err := env.contentInUse(ctx, post)
+	if err != nil {
+		return err
+	}
+
+	if err := env.PostsRepository.PurgePost(ctx, post.ID); err != nil {
+		return err
+	}
+
+	// Uploads may reuse a filename, so another post can still be pointing at the same file
+	if !shared {
+		if err := env.ContentService.DeleteContent(ctx, post.Body); err != nil {
+			log.Printf("failed to delete content of purged post %d (file: %s): %v", post.ID, post.Body, err)
+		}
+	}
+
+	for _, rev := range revisions {
+		if rev.Content == "" {
+			continue
+		}
+		if err := env.ContentService.DeleteRevision(ctx, rev.Content); err != nil {
+			log.Printf("failed to delete revision %d of purged post %d: %v", rev.Number, post.ID, err)
+		}
+	}
+
+	env.deletePostAssets(ctx, post.ID)
+
+	return nil
+}
+
+// Helper function to report whether any other post, in the trash or not, uses a post's content file
+func (env Env) contentInUse(ctx context.Context, post posts.Post) (bool, error) {
+	live, err := env.PostsRepository.GetPosts(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	deleted, err := env.PostsRepository.GetDeletedPosts(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	for _, other := range append(live, deleted...) {
+		if other.ID != post.ID && other.Body == post.Body {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
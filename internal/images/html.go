@@ -0,0 +1,121 @@
+package images
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// MediaPrefix is the URL path uploaded assets are served under
+const MediaPrefix = "/media/"
+
+// Sizes tells browsers how wide post images are displayed, matching the post column width
+const Sizes = "(max-width: 900px) 100vw, 900px"
+
+// RewriteHTML adds responsive srcsets to the uploaded images in rendered post HTML.
+// lookup returns the manifest of an asset, and images without one are left as they are.
+// Images that already have a srcset are left alone so authors can still hand-tune markup.
+func RewriteHTML(htmlContent string, lookup func(name string) (Manifest, bool)) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(htmlContent))
+
+	var b strings.Builder
+	pictures := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			return b.String()
+		}
+
+		// Everything not rewritten is copied byte for byte
+		raw := string(tokenizer.Raw())
+		if tokenType != nethtml.StartTagToken && tokenType != nethtml.SelfClosingTagToken && tokenType != nethtml.EndTagToken {
+			b.WriteString(raw)
+			continue
+		}
+
+		token := tokenizer.Token()
+		switch {
+		case token.Data == "picture" && tokenType == nethtml.StartTagToken:
+			pictures++
+		case token.Data == "picture" && tokenType == nethtml.EndTagToken && pictures > 0:
+			pictures--
+		case token.Data == "img" && tokenType != nethtml.EndTagToken:
+			if rewritten, ok := rewriteImage(token, pictures > 0, lookup); ok {
+				b.WriteString(rewritten)
+				continue
+			}
+		}
+
+		b.WriteString(raw)
+	}
+}
+
+func rewriteImage(token nethtml.Token, inPicture bool, lookup func(name string) (Manifest, bool)) (string, bool) {
+	attrs := make(map[string]string)
+	for _, attr := range token.Attr {
+		attrs[attr.Key] = attr.Val
+	}
+
+	src := attrs["src"]
+	if _, ok := attrs["srcset"]; ok || !strings.HasPrefix(src, MediaPrefix) {
+		return "", false
+	}
+
+	// Query strings and fragments are not part of the asset name
+	name := strings.TrimPrefix(src, MediaPrefix)
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+
+	manifest, ok := lookup(name)
+	if !ok || len(manifest.Variants) == 0 {
+		return "", false
+	}
+
+	var fallback, webp []string
+	for _, variant := range manifest.Variants {
+		width := " " + strconv.Itoa(variant.Width) + "w"
+		fallback = append(fallback, MediaPrefix+variant.Name+width)
+		webp = append(webp, MediaPrefix+variant.WebP+width)
+	}
+
+	token.Attr = append(token.Attr,
+		nethtml.Attribute{Key: "srcset", Val: strings.Join(fallback, ", ")},
+		nethtml.Attribute{Key: "sizes", Val: Sizes},
+	)
+
+	// Dimensions let browsers reserve space before the image loads
+	if _, ok := attrs["width"]; !ok {
+		if _, ok := attrs["height"]; !ok {
+			token.Attr = append(token.Attr,
+				nethtml.Attribute{Key: "width", Val: strconv.Itoa(manifest.Width)},
+				nethtml.Attribute{Key: "height", Val: strconv.Itoa(manifest.Height)},
+			)
+		}
+	}
+
+	img := renderTag(token)
+
+	// An existing <picture> already chooses its sources, so only the fallback <img> is extended
+	if inPicture || !manifest.HasWebP() {
+		return img, true
+	}
+
+	return `<picture><source type="image/webp" srcset="` + html.EscapeString(strings.Join(webp, ", ")) +
+		`" sizes="` + html.EscapeString(Sizes) + `">` + img + `</picture>`, true
+}
+
+func renderTag(token nethtml.Token) string {
+	var b strings.Builder
+	b.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Type == nethtml.SelfClosingTagToken {
+		b.WriteString(" /")
+	}
+	b.WriteString(">")
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package images
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteHTML(t *testing.T) {
+	manifests := map[string]Manifest{
+		"1/photo.jpg": {Width: 1000, Height: 500, Variants: []Variant{
+			{Width: 320, Name: "1/variants/photo-320w.jpg", WebP: "1/variants/photo-320w.webp"},
+			{Width: 1000, Name: "1/photo.jpg", WebP: "1/variants/photo-1000w.webp"},
+		}},
+		"1/chart.png": {Width: 800, Height: 400, Variants: []Variant{
+			{Width: 320, Name: "1/variants/chart-320w.png", WebP: "1/variants/chart-320w.webp"},
+			{Width: 800, Name: "1/chart.png"},
+		}},
+	}
+	lookup := func(name string) (Manifest, bool) {
+		manifest, ok := manifests[name]
+		return manifest, ok
+	}
+
+	t.Run("wraps images with WebP variants in a picture", func(t *testing.T) {
+		got := RewriteHTML(`<p>Before <img src="/media/1/photo.jpg" alt="A &amp; B"> after</p>`, lookup)
+		expected := `<p>Before <picture><source type="image/webp" srcset="/media/1/variants/photo-320w.webp 320w, /media/1/variants/photo-1000w.webp 1000w" sizes="` + Sizes + `">` +
+			`<img src="/media/1/photo.jpg" alt="A &amp; B" srcset="/media/1/variants/photo-320w.jpg 320w, /media/1/photo.jpg 1000w" sizes="` + Sizes + `" width="1000" height="500"></picture> after</p>`
+
+		if got != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, got)
+		}
+	})
+
+	t.Run("adds only a srcset without complete WebP variants", func(t *testing.T) {
+		got := RewriteHTML(`<img src="/media/1/chart.png" width="400">`, lookup)
+		expected := `<img src="/media/1/chart.png" width="400" srcset="/media/1/variants/chart-320w.png 320w, /media/1/chart.png 800w" sizes="` + Sizes + `">`
+
+		if got != expected {
+			t.Errorf("expected\n%s\ngot\n%s", expected, got)
+		}
+	})
+
+	t.Run("does not nest pictures", func(t *testing.T) {
+		got := RewriteHTML(`<picture><source srcset="/x.avif"><img src="/media/1/photo.jpg"></picture>`, lookup)
+
+		if strings.Count(got, "<picture>") != 1 || !strings.Contains(got, `srcset="/media/1/variants/photo-320w.jpg 320w`) {
+			t.Errorf("unexpected output %s", got)
+		}
+	})
+
+	t.Run("leaves other markup untouched", func(t *testing.T) {
+		inputs := []string{
+			`<IMG SRC="https://example.com/a.jpg"><!-- note --><p class=x>text &copy;</p>`,
+			`<img src="/media/1/photo.jpg" srcset="/custom.jpg 1x">`,
+			`<img src="/media/1/unknown.jpg">`,
+		}
+
+		for _, input := range inputs {
+			if got := RewriteHTML(input, lookup); got != input {
+				t.Errorf("expected %s unchanged, got %s", input, got)
+			}
+		}
+	})
+}
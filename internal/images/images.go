@@ -0,0 +1,261 @@
+package images
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Widths are the image widths, in pixels, generated for responsive srcsets.
+// Only widths smaller than the uploaded image are generated.
+var Widths = []int{320, 640, 960, 1280, 1920}
+
+// VariantsDirectory holds the generated variants next to the images they belong to
+const VariantsDirectory = "variants"
+
+// MaxPixels bounds the decoded size of an upload so a small file can't expand into gigabytes of memory
+const MaxPixels = 50_000_000
+
+// JPEG qualities for the re-encoded original and its smaller variants
+const (
+	originalQuality = 90
+	variantQuality  = 82
+)
+
+// ErrUnsupported is returned for images that are stored as uploaded rather than processed
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Manifest records the variants generated for an image so rendered HTML can reference them
+type Manifest struct {
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant is one width of an image. Name is in the image's own format and WebP, when set,
+// is a smaller WebP encoding of the same width. The widest variant is the original.
+type Variant struct {
+	Width int    `json:"width"`
+	Name  string `json:"name"`
+	WebP  string `json:"webp,omitempty"`
+}
+
+// File is an encoded image ready to be stored as an asset
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Result is a processed upload: the original without metadata, its variants and their manifest. Animated
+// WebP images can't be decoded, so they are kept whole without variants.
+type Result struct {
+	Original File
+	Variants []File
+	Manifest Manifest
+}
+
+// CanProcess reports whether an asset is an image format the pipeline handles.
+// GIFs are left alone so animations survive, and SVGs are already resolution independent.
+func CanProcess(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return true
+	}
+	return false
+}
+
+// IsVariant reports whether an asset name belongs to a generated variant or manifest
+func IsVariant(name string) bool {
+	return strings.Contains("/"+name, "/"+VariantsDirectory+"/")
+}
+
+// ManifestName returns the asset name of an image's manifest
+func ManifestName(name string) string {
+	return path.Join(path.Dir(name), VariantsDirectory, path.Base(name)+".json")
+}
+
+// VariantName returns the asset name of an image variant at the given width with the given extension
+func VariantName(name string, width int, ext string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	return path.Join(path.Dir(name), VariantsDirectory, base+"-"+strconv.Itoa(width)+"w"+ext)
+}
+
+// Process strips an uploaded image's EXIF and XMP metadata and generates smaller variants in its own format
+// and WebP. The original is only re-encoded when its EXIF orientation has to be applied to the pixels;
+// otherwise the metadata is cut out of the file and the image data is kept as uploaded.
+func Process(name string, data []byte) (Result, error) {
+	if !CanProcess(name) {
+		return Result{}, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read image header: %w", err)
+	}
+	if config.Width*config.Height > MaxPixels {
+		return Result{}, fmt.Errorf("image too large: %dx%d", config.Width, config.Height)
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if ext == ".webp" && isAnimatedWebP(data) {
+		return animated(name, data, config)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	// Metadata is cut out of the uploaded file, unless the orientation it describes has to be applied to the
+	// pixels, which means re-encoding
+	original, stripped := stripMetadata(ext, data)
+	if orientation > 1 || !stripped {
+		img = orient(img, orientation)
+		original, err = encode(img, ext, originalQuality)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	bounds := img.Bounds()
+
+	result := Result{
+		Original: File{Name: name, Data: original},
+		Manifest: Manifest{Width: bounds.Dx(), Height: bounds.Dy()},
+	}
+
+	for _, width := range append(widthsBelow(bounds.Dx()), bounds.Dx()) {
+		variant := Variant{Width: width, Name: name}
+		scaled := img
+		fallback := original
+
+		if width < bounds.Dx() {
+			scaled = resize(img, width)
+			fallback, err = encode(scaled, ext, variantQuality)
+			if err != nil {
+				return Result{}, err
+			}
+
+			variant.Name = VariantName(name, width, ext)
+			result.Variants = append(result.Variants, File{Name: variant.Name, Data: fallback})
+		}
+
+		// The WebP encoder is lossless, so keep its output only when it actually saves bytes
+		if ext != ".webp" {
+			webp, err := encode(scaled, ".webp", 0)
+			if err != nil {
+				return Result{}, err
+			}
+			if len(webp) < len(fallback) {
+				variant.WebP = VariantName(name, width, ".webp")
+				result.Variants = append(result.Variants, File{Name: variant.WebP, Data: webp})
+			}
+		}
+
+		result.Manifest.Variants = append(result.Manifest.Variants, variant)
+	}
+
+	manifest, err := json.Marshal(result.Manifest)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode image manifest: %w", err)
+	}
+	result.Variants = append(result.Variants, File{Name: ManifestName(name), Data: manifest})
+
+	return result, nil
+}
+
+// Helper function to keep an animated WebP upload as it is apart from its metadata, with the original as
+// its only width
+func animated(name string, data []byte, config image.Config) (Result, error) {
+	original, ok := stripWebPMetadata(data)
+	if !ok {
+		return Result{}, errors.New("failed to read animated WebP image")
+	}
+
+	result := Result{
+		Original: File{Name: name, Data: original},
+		Manifest: Manifest{
+			Width:    config.Width,
+			Height:   config.Height,
+			Variants: []Variant{{Width: config.Width, Name: name}},
+		},
+	}
+
+	manifest, err := json.Marshal(result.Manifest)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode image manifest: %w", err)
+	}
+	result.Variants = []File{{Name: ManifestName(name), Data: manifest}}
+
+	return result, nil
+}
+
+// HasWebP reports whether every variant has a WebP encoding, so a WebP srcset covers every width
+func (m Manifest) HasWebP() bool {
+	for _, variant := range m.Variants {
+		if variant.WebP == "" {
+			return false
+		}
+	}
+	return len(m.Variants) > 0
+}
+
+func widthsBelow(width int) []int {
+	var widths []int
+	for _, w := range Widths {
+		if w < width {
+			widths = append(widths, w)
+		}
+	}
+	return widths
+}
+
+func resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, ext string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch ext {
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case ".png":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, img)
+	case ".webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		return nil, ErrUnsupported
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s image: %w", ext, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,315 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+func TestProcess(t *testing.T) {
+	t.Run("generates smaller widths", func(t *testing.T) {
+		result, err := Process("12/photo.png", encodePNG(t, 700, 100))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Manifest.Width != 700 || result.Manifest.Height != 100 {
+			t.Errorf("expected 700x100 manifest, got %dx%d", result.Manifest.Width, result.Manifest.Height)
+		}
+
+		var widths []int
+		for _, variant := range result.Manifest.Variants {
+			widths = append(widths, variant.Width)
+		}
+		if len(widths) != 3 || widths[0] != 320 || widths[1] != 640 || widths[2] != 700 {
+			t.Fatalf("expected widths [320 640 700], got %v", widths)
+		}
+
+		if result.Manifest.Variants[2].Name != "12/photo.png" {
+			t.Errorf("expected widest variant to be the original, got %s", result.Manifest.Variants[2].Name)
+		}
+
+		files := make(map[string][]byte)
+		for _, file := range result.Variants {
+			files[file.Name] = file.Data
+		}
+
+		data, ok := files["12/variants/photo-320w.png"]
+		if !ok {
+			t.Fatalf("expected 320w variant, got %v", result.Variants)
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || config.Width != 320 || config.Height != 45 {
+			t.Errorf("expected 320x45 variant, got %dx%d (%v)", config.Width, config.Height, err)
+		}
+
+		if _, ok := files["12/variants/photo.png.json"]; !ok {
+			t.Errorf("expected manifest among variants")
+		}
+	})
+
+	t.Run("small images keep only their original", func(t *testing.T) {
+		data := encodePNG(t, 100, 100)
+		result, err := Process("1/icon.png", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(result.Manifest.Variants) != 1 || result.Manifest.Variants[0].Width != 100 {
+			t.Errorf("expected only the original width, got %v", result.Manifest.Variants)
+		}
+		if !bytes.Equal(result.Original.Data, data) {
+			t.Errorf("expected the uploaded PNG to be kept as it is")
+		}
+	})
+
+	t.Run("keeps JPEGs without metadata as uploaded", func(t *testing.T) {
+		data := encodeJPEG(t, 40, 20)
+		result, err := Process("1/photo.jpg", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(result.Original.Data, data) {
+			t.Errorf("expected the uploaded JPEG to be kept as it is")
+		}
+	})
+
+	t.Run("strips EXIF without re-encoding when upright", func(t *testing.T) {
+		data := encodeJPEG(t, 40, 20)
+		result, err := Process("1/photo.jpg", withOrientation(data, 1))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(result.Original.Data, data) {
+			t.Errorf("expected the JPEG without its EXIF segment, got %d bytes from %d", len(result.Original.Data), len(data))
+		}
+	})
+
+	t.Run("strips WebP metadata chunks without re-encoding", func(t *testing.T) {
+		vp8l := encodeWebP(t, 40, 20)
+		data := webpFile(
+			webpChunk("VP8X", vp8x(webpEXIFFlag|webpXMPFlag, 40, 20)),
+			vp8l,
+			webpChunk("EXIF", []byte("MM\x00\x2a\x00\x00\x00\x08\x00")),
+			webpChunk("XMP ", []byte("<x:xmpmeta/>")),
+		)
+
+		result, err := Process("1/photo.webp", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := webpFile(webpChunk("VP8X", vp8x(0, 40, 20)), vp8l)
+		if !bytes.Equal(result.Original.Data, expected) {
+			t.Errorf("expected only the metadata chunks and flags to be removed")
+		}
+
+		config, _, err := image.DecodeConfig(bytes.NewReader(result.Original.Data))
+		if err != nil || config.Width != 40 || config.Height != 20 {
+			t.Errorf("expected a readable 40x20 WebP, got %dx%d (%v)", config.Width, config.Height, err)
+		}
+	})
+
+	t.Run("keeps animated WebP images whole", func(t *testing.T) {
+		frame := append(make([]byte, 16), encodeWebP(t, 700, 100)...)
+		data := webpFile(
+			webpChunk("VP8X", vp8x(webpAnimationFlag|webpEXIFFlag, 700, 100)),
+			webpChunk("ANIM", make([]byte, 6)),
+			webpChunk("ANMF", frame),
+			webpChunk("EXIF", []byte("MM\x00\x2a")),
+		)
+
+		result, err := Process("1/anim.webp", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := webpFile(
+			webpChunk("VP8X", vp8x(webpAnimationFlag, 700, 100)),
+			webpChunk("ANIM", make([]byte, 6)),
+			webpChunk("ANMF", frame),
+		)
+		if !bytes.Equal(result.Original.Data, expected) {
+			t.Errorf("expected the animation without its metadata")
+		}
+
+		if result.Manifest.Width != 700 || len(result.Manifest.Variants) != 1 || result.Manifest.Variants[0].Name != "1/anim.webp" {
+			t.Errorf("expected the original as the only width, got %+v", result.Manifest)
+		}
+		if len(result.Variants) != 1 || result.Variants[0].Name != "1/variants/anim.webp.json" {
+			t.Errorf("expected only the manifest among variants, got %d files", len(result.Variants))
+		}
+	})
+
+	t.Run("applies and strips EXIF orientation", func(t *testing.T) {
+		// Left half red, right half blue, shot with the camera rotated
+		img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 40; x++ {
+				if x < 20 {
+					img.Set(x, y, color.RGBA{255, 0, 0, 255})
+				} else {
+					img.Set(x, y, color.RGBA{0, 0, 255, 255})
+				}
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := Process("1/photo.jpg", withOrientation(buf.Bytes(), 6))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bytes.Contains(result.Original.Data, []byte("Exif")) {
+			t.Errorf("expected EXIF metadata to be stripped")
+		}
+
+		decoded, err := jpeg.Decode(bytes.NewReader(result.Original.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bounds := decoded.Bounds(); bounds.Dx() != 20 || bounds.Dy() != 40 {
+			t.Fatalf("expected rotated 20x40 image, got %dx%d", bounds.Dx(), bounds.Dy())
+		}
+
+		// Rotating clockwise moves the left half to the top
+		if r, _, b, _ := decoded.At(10, 5).RGBA(); r < b {
+			t.Errorf("expected red at the top, got r=%d b=%d", r, b)
+		}
+		if r, _, b, _ := decoded.At(10, 35).RGBA(); b < r {
+			t.Errorf("expected blue at the bottom, got r=%d b=%d", r, b)
+		}
+	})
+
+	t.Run("rejects unsupported formats", func(t *testing.T) {
+		if _, err := Process("1/anim.gif", []byte("GIF89a")); err != ErrUnsupported {
+			t.Errorf("expected ErrUnsupported, got %v", err)
+		}
+	})
+
+	t.Run("rejects undecodable data", func(t *testing.T) {
+		if _, err := Process("1/broken.png", []byte("not an image")); err == nil {
+			t.Errorf("expected error for invalid image")
+		}
+	})
+}
+
+func TestNames(t *testing.T) {
+	if got := VariantName("12/photo.jpg", 640, ".webp"); got != "12/variants/photo-640w.webp" {
+		t.Errorf("unexpected variant name %s", got)
+	}
+	if got := ManifestName("12/photo.jpg"); got != "12/variants/photo.jpg.json" {
+		t.Errorf("unexpected manifest name %s", got)
+	}
+	if !IsVariant("12/variants/photo-640w.webp") || IsVariant("12/photo.jpg") {
+		t.Errorf("unexpected IsVariant result")
+	}
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(encodePNG(t, width, height)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeWebP returns the VP8L chunk of a lossless WebP image, without its RIFF header
+func encodeWebP(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(encodePNG(t, width, height)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()[12:]
+}
+
+// webpFile wraps chunks in a RIFF WebP header
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+
+	out := binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body)))
+	return append(out, body...)
+}
+
+// webpChunk builds a RIFF chunk, padded to an even length
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := binary.LittleEndian.AppendUint32([]byte(fourCC), uint32(len(payload)))
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// vp8x builds the payload of an extended WebP header
+func vp8x(flags byte, width, height int) []byte {
+	payload := []byte{flags, 0, 0, 0}
+	payload = append(payload, byte(width-1), byte((width-1)>>8), byte((width-1)>>16))
+	return append(payload, byte(height-1), byte((height-1)>>8), byte((height-1)>>16))
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after the JPEG start marker
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
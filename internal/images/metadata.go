@@ -0,0 +1,155 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Bits of the VP8X flags byte of an extended WebP file
+const (
+	webpAnimationFlag = 1 << 1
+	webpXMPFlag       = 1 << 2
+	webpEXIFFlag      = 1 << 3
+)
+
+// stripMetadata removes EXIF and XMP metadata from an encoded image without decoding it, so the image data
+// is stored exactly as uploaded. It reports false if the file's structure couldn't be followed.
+func stripMetadata(ext string, data []byte) ([]byte, bool) {
+	switch ext {
+	case ".jpg", ".jpeg":
+		return stripJPEGMetadata(data)
+	case ".png":
+		return stripPNGMetadata(data)
+	case ".webp":
+		return stripWebPMetadata(data)
+	}
+	return nil, false
+}
+
+// stripJPEGMetadata drops the APP1 segments holding EXIF or XMP, keeping every other segment such as the
+// JFIF header and ICC profile
+func stripJPEGMetadata(data []byte) ([]byte, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, false
+	}
+
+	out := append([]byte{}, data[:2]...)
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, false
+		}
+
+		marker := data[i+1]
+		if marker == 0xDA {
+			// The compressed image data follows the start of scan, and is kept as it is
+			return append(out, data[i:]...), true
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, false
+		}
+
+		segment := data[i+4 : i+2+length]
+		metadata := marker == 0xE1 && (bytes.HasPrefix(segment, []byte("Exif\x00")) || bytes.HasPrefix(segment, []byte("http://ns.adobe.com/")))
+		if !metadata {
+			out = append(out, data[i:i+2+length]...)
+		}
+
+		i += 2 + length
+	}
+}
+
+// stripPNGMetadata drops the eXIf chunk and the text chunks, which is where XMP is kept
+func stripPNGMetadata(data []byte) ([]byte, bool) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, false
+	}
+
+	out := append([]byte{}, signature...)
+	for i := len(signature); ; {
+		if i+12 > len(data) {
+			return nil, false
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, false
+		}
+
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "iTXt", "zTXt":
+		case "IEND":
+			return append(out, data[i:end]...), true
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP file and clears the flags announcing them, leaving
+// the image data, lossy or lossless and animated or not, as it was
+func stripWebPMetadata(data []byte) ([]byte, bool) {
+	chunks, ok := webpChunks(data)
+	if !ok {
+		return nil, false
+	}
+
+	out := append([]byte{}, data[:12]...)
+	for _, chunk := range chunks {
+		switch string(chunk[:4]) {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			chunk = append([]byte{}, chunk...)
+			chunk[8] &^= webpEXIFFlag | webpXMPFlag
+		}
+		out = append(out, chunk...)
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true
+}
+
+// isAnimatedWebP reports whether a WebP file holds an animation, which the decoder doesn't support
+func isAnimatedWebP(data []byte) bool {
+	chunks, ok := webpChunks(data)
+	if !ok {
+		return false
+	}
+
+	for _, chunk := range chunks {
+		if string(chunk[:4]) == "VP8X" && len(chunk) > 8 {
+			return chunk[8]&webpAnimationFlag != 0
+		}
+	}
+	return false
+}
+
+// Helper function to split a WebP file into its chunks, each with its header and padding
+func webpChunks(data []byte) ([][]byte, bool) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, false
+	}
+
+	var chunks [][]byte
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, false
+		}
+
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, false
+		}
+
+		chunks = append(chunks, data[i:end])
+		i = end
+	}
+	return chunks, true
+}
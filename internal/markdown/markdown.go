@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"website/internal/frontmatter"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Document is a rendered Markdown post along with its front matter
+type Document struct {
+	frontmatter.Metadata
+	HTML string
+}
+
+// converter renders CommonMark with the GitHub Flavored Markdown extensions (tables, strikethrough, autolinks, task lists)
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// IsMarkdown reports whether the filename has a Markdown extension
+func IsMarkdown(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// Render parses the optional front matter block and converts the remaining Markdown source to HTML
+func Render(source string) (Document, error) {
+	meta, body, err := frontmatter.Parse(source)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var buf bytes.Buffer
+	if err := converter.Convert([]byte(body), &buf); err != nil {
+		return Document{}, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	return Document{
+		Metadata: meta,
+		HTML:     buf.String(),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsMarkdown(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected bool
+	}{
+		{"post.md", true},
+		{"post.MD", true},
+		{"post.markdown", true},
+		{"post.html", false},
+		{"post", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := IsMarkdown(tt.filename); got != tt.expected {
+				t.Errorf("expected IsMarkdown(%q) to be %v, got %v", tt.filename, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("front matter and body", func(t *testing.T) {
+		source := "---\ntitle: Hello World\ndescription: A first post\n---\n# Heading\n\nSome *text*.\n"
+
+		doc, err := Render(source)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if doc.Title != "Hello World" {
+			t.Errorf("expected Title 'Hello World', got %q", doc.Title)
+		}
+		if doc.Description != "A first post" {
+			t.Errorf("expected Description 'A first post', got %q", doc.Description)
+		}
+		if !strings.Contains(doc.HTML, "<h1>Heading</h1>") {
+			t.Errorf("expected rendered heading, got %q", doc.HTML)
+		}
+		if strings.Contains(doc.HTML, "title:") {
+			t.Errorf("expected front matter to be stripped, got %q", doc.HTML)
+		}
+	})
+
+	t.Run("no front matter", func(t *testing.T) {
+		doc, err := Render("Just a paragraph.")
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if doc.Title != "" {
+			t.Errorf("expected empty Title, got %q", doc.Title)
+		}
+		if !strings.Contains(doc.HTML, "<p>Just a paragraph.</p>") {
+			t.Errorf("expected paragraph, got %q", doc.HTML)
+		}
+	})
+
+	t.Run("gfm tables and fenced code", func(t *testing.T) {
+		source := "| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(\"hi\")\n```\n"
+
+		doc, err := Render(source)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !strings.Contains(doc.HTML, "<table>") {
+			t.Errorf("expected table, got %q", doc.HTML)
+		}
+		if !strings.Contains(doc.HTML, `<code class="language-go">`) {
+			t.Errorf("expected fenced code block, got %q", doc.HTML)
+		}
+	})
+
+	t.Run("unterminated front matter", func(t *testing.T) {
+		_, err := Render("---\ntitle: Broken\n# Heading\n")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
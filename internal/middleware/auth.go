@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"firebase.google.com/go/v4/auth"
 	"log"
 	"net/http"
@@ -27,7 +26,7 @@ func Auth(firebaseAuth *auth.Client) func(http.Handler) http.Handler {
 			idToken := cookie.Value
 
 			// Verify the ID token with Firebase
-			_, err = firebaseAuth.VerifyIDToken(context.Background(), idToken)
+			_, err = firebaseAuth.VerifyIDToken(r.Context(), idToken)
 			if err != nil {
 				log.Printf("firebase token verification failed: %v", err)
 				http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
@@ -2,7 +2,10 @@ package parse
 
 import (
 	"html/template"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 func Parse() map[string]*template.Template {
@@ -21,6 +24,25 @@ func Parse() map[string]*template.Template {
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		// pageURL links to a page of a paginated list whose base path may already carry a query string
+		"pageURL": func(basePath string, page int) string {
+			if strings.Contains(basePath, "?") {
+				return basePath + "&page=" + strconv.Itoa(page)
+			}
+			return basePath + "?page=" + strconv.Itoa(page)
+		},
+		// cursorURL links to a neighbouring page, adding the cursor that fetches it by keyset when the list has one
+		"cursorURL": func(basePath string, page int, cursor string) string {
+			separator := "?"
+			if strings.Contains(basePath, "?") {
+				separator = "&"
+			}
+			link := basePath + separator + "page=" + strconv.Itoa(page)
+			if cursor != "" {
+				link += "&cursor=" + url.QueryEscape(cursor)
+			}
+			return link
+		},
 	}
 	
 	// Parse common files into base template
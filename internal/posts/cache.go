@@ -0,0 +1,229 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"website/internal/cache"
+)
+
+// CachedRepository decorates a Repository with an in-memory cache of posts, post lists and counts.
+// Any write through it clears the whole cache, since a single post can appear on many cached pages, and
+// concurrent misses for the same entry share one read of the wrapped repository. Entries also expire
+// after a time to live, which bounds how late scheduled posts appear and how long writes made by
+// another instance go unnoticed.
+type CachedRepository struct {
+	next  Repository
+	cache *cache.LRU[any]
+	group singleflight.Group
+
+	// generation changes on every write so reads that raced with one neither cache nor share what they read
+	generation atomic.Uint64
+}
+
+// NewCachedRepository wraps an existing repository with a cache of up to maxEntries lists and posts,
+// each kept for at most ttl
+func NewCachedRepository(next Repository, maxEntries int, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		next:  next,
+		cache: cache.New(int64(maxEntries), ttl, func(any) int64 { return 1 }),
+	}
+}
+
+// Stats returns the hit and miss counts of the repository cache
+func (r *CachedRepository) Stats() cache.Stats {
+	return r.cache.Stats()
+}
+
+// postPage is a cached page of posts along with its pagination
+type postPage struct {
+	posts      []Post
+	pagination PaginationInfo
+}
+
+func (r *CachedRepository) GetPost(ctx context.Context, id int) (*Post, error) {
+	post, err := cached(ctx, r, fmt.Sprintf("post:%d", id), func(ctx context.Context) (Post, error) {
+		post, err := r.next.GetPost(ctx, id)
+		if err != nil {
+			return Post{}, err
+		}
+		return *post, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clonePost(post), nil
+}
+
+func (r *CachedRepository) GetPostBySlug(ctx context.Context, slug string) (*Post, error) {
+	post, err := cached(ctx, r, "slug:"+slug, func(ctx context.Context) (Post, error) {
+		post, err := r.next.GetPostBySlug(ctx, slug)
+		if err != nil {
+			return Post{}, err
+		}
+		return *post, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clonePost(post), nil
+}
+
+func (r *CachedRepository) GetPosts(ctx context.Context) ([]Post, error) {
+	list, err := cached(ctx, r, "posts", r.next.GetPosts)
+	return slices.Clone(list), err
+}
+
+func (r *CachedRepository) GetPostsPaginated(ctx context.Context, page int, cursor string) ([]Post, PaginationInfo, error) {
+	result, err := cached(ctx, r, fmt.Sprintf("page:%d:%s", page, cursor), func(ctx context.Context) (postPage, error) {
+		list, pagination, err := r.next.GetPostsPaginated(ctx, page, cursor)
+		return postPage{list, pagination}, err
+	})
+	return slices.Clone(result.posts), result.pagination, err
+}
+
+func (r *CachedRepository) GetPostsByTagPaginated(ctx context.Context, tag string, page int) ([]Post, PaginationInfo, error) {
+	result, err := cached(ctx, r, fmt.Sprintf("tag:%d:%s", page, tag), func(ctx context.Context) (postPage, error) {
+		list, pagination, err := r.next.GetPostsByTagPaginated(ctx, tag, page)
+		return postPage{list, pagination}, err
+	})
+	return slices.Clone(result.posts), result.pagination, err
+}
+
+func (r *CachedRepository) GetTagCounts(ctx context.Context) ([]TagCount, error) {
+	counts, err := cached(ctx, r, "tags", r.next.GetTagCounts)
+	return slices.Clone(counts), err
+}
+
+func (r *CachedRepository) GetTotalPostsCount(ctx context.Context) (int, error) {
+	return cached(ctx, r, "count", r.next.GetTotalPostsCount)
+}
+
+// SearchPosts is not cached since queries rarely repeat
+func (r *CachedRepository) SearchPosts(ctx context.Context, query string, page int) ([]SearchResult, PaginationInfo, error) {
+	return r.next.SearchPosts(ctx, query, page)
+}
+
+// IndexPost passes through since search results are not cached
+func (r *CachedRepository) IndexPost(ctx context.Context, post Post, text string) error {
+	return r.next.IndexPost(ctx, post, text)
+}
+
+func (r *CachedRepository) DeletePost(ctx context.Context, id int) error {
+	defer r.invalidate()
+	return r.next.DeletePost(ctx, id)
+}
+
+// GetDeletedPosts is not cached since only the trash view reads it
+func (r *CachedRepository) GetDeletedPosts(ctx context.Context) ([]Post, error) {
+	return r.next.GetDeletedPosts(ctx)
+}
+
+func (r *CachedRepository) RestorePost(ctx context.Context, id int) error {
+	defer r.invalidate()
+	return r.next.RestorePost(ctx, id)
+}
+
+func (r *CachedRepository) PurgePost(ctx context.Context, id int) error {
+	defer r.invalidate()
+	return r.next.PurgePost(ctx, id)
+}
+
+func (r *CachedRepository) UpdatePost(ctx context.Context, post Post) error {
+	defer r.invalidate()
+	return r.next.UpdatePost(ctx, post)
+}
+
+func (r *CachedRepository) UpdatePostStatus(ctx context.Context, id int, status Status, publishAt time.Time) error {
+	defer r.invalidate()
+	return r.next.UpdatePostStatus(ctx, id, status, publishAt)
+}
+
+func (r *CachedRepository) UpdatePostBody(ctx context.Context, id int, body, filename string) error {
+	defer r.invalidate()
+	return r.next.UpdatePostBody(ctx, id, body, filename)
+}
+
+// ReservePostID passes through since reserving an id changes no post
+func (r *CachedRepository) ReservePostID(ctx context.Context) (int, error) {
+	return r.next.ReservePostID(ctx)
+}
+
+func (r *CachedRepository) CreatePost(ctx context.Context, post Post) (int, error) {
+	defer r.invalidate()
+	return r.next.CreatePost(ctx, post)
+}
+
+func (r *CachedRepository) ImportPost(ctx context.Context, post Post) error {
+	defer r.invalidate()
+	return r.next.ImportPost(ctx, post)
+}
+
+// Revisions are only read from the admin edit view, so they pass through uncached
+func (r *CachedRepository) CreateRevision(ctx context.Context, rev Revision) (Revision, error) {
+	return r.next.CreateRevision(ctx, rev)
+}
+
+func (r *CachedRepository) GetRevisions(ctx context.Context, postID int) ([]Revision, error) {
+	return r.next.GetRevisions(ctx, postID)
+}
+
+func (r *CachedRepository) GetRevision(ctx context.Context, postID, number int) (*Revision, error) {
+	return r.next.GetRevision(ctx, postID, number)
+}
+
+// Helper function to serve a value from the cache, loading it once for all concurrent callers on a miss.
+// The shared load is not cancelled with any one caller's context, so a caller that gives up leaves it running
+// for the others, bounded by the wrapped repository's own timeouts. Errors are not cached.
+func cached[T any](ctx context.Context, r *CachedRepository, key string, load func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if value, ok := r.cache.Get(key); ok {
+		return value.(T), nil
+	}
+
+	// Callers arriving after a write start a new load rather than joining one that may have read stale data
+	generation := r.generation.Load()
+	results := r.group.DoChan(fmt.Sprintf("%d/%s", generation, key), func() (any, error) {
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if r.generation.Load() == generation {
+			r.cache.Set(key, value)
+		}
+		return value, nil
+	})
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Helper function to clear the cache once a write has finished, whether or not it succeeded
+func (r *CachedRepository) invalidate() {
+	r.generation.Add(1)
+	r.cache.Clear()
+}
+
+// Helper function to copy a cached post so callers can change it without changing the cache
+func clonePost(post Post) *Post {
+	post.Tags = slices.Clone(post.Tags)
+	post.Sanitized = slices.Clone(post.Sanitized)
+	if post.DeletedAt != nil {
+		deletedAt := *post.DeletedAt
+		post.DeletedAt = &deletedAt
+	}
+	return &post
+}
@@ -0,0 +1,142 @@
+package posts
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingRepository is a Repository that counts the reads reaching it, optionally holding them until released
+type countingRepository struct {
+	Repository
+	post    Post
+	reads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingRepository) GetPost(ctx context.Context, id int) (*Post, error) {
+	r.reads.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	post := r.post
+	return &post, nil
+}
+
+func (r *countingRepository) GetTotalPostsCount(ctx context.Context) (int, error) {
+	r.reads.Add(1)
+	return 1, nil
+}
+
+func (r *countingRepository) UpdatePost(ctx context.Context, post Post) error {
+	r.post = post
+	return nil
+}
+
+func TestCachedRepository(t *testing.T) {
+	next := &countingRepository{post: Post{ID: 1, Title: "Original", Tags: []string{"go"}}}
+	repo := NewCachedRepository(next, 100, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		post, err := repo.GetPost(context.Background(), 1)
+		if err != nil || post.Title != "Original" {
+			t.Fatalf("expected cached post, got %+v (%v)", post, err)
+		}
+
+		// Changing the returned post must not change the cached one
+		post.Title = "Changed"
+		post.Tags[0] = "changed"
+	}
+	if count, _ := repo.GetTotalPostsCount(context.Background()); count != 1 {
+		t.Errorf("expected count 1, got %d", count)
+	}
+	repo.GetTotalPostsCount(context.Background())
+
+	if reads := next.reads.Load(); reads != 2 {
+		t.Errorf("expected one read each of the post and the count, got %d", reads)
+	}
+
+	post, _ := repo.GetPost(context.Background(), 1)
+	if post.Tags[0] != "go" {
+		t.Errorf("expected cached tags to be unchanged, got %v", post.Tags)
+	}
+
+	// Writes clear every cached entry, including the counts
+	if err := repo.UpdatePost(context.Background(), Post{ID: 1, Title: "Updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if post, _ := repo.GetPost(context.Background(), 1); post.Title != "Updated" {
+		t.Errorf("expected the updated post after invalidation, got %q", post.Title)
+	}
+	repo.GetTotalPostsCount(context.Background())
+	if reads := next.reads.Load(); reads != 4 {
+		t.Errorf("expected the post and count to be read again, got %d reads", reads)
+	}
+
+	stats := repo.Stats()
+	if stats.Hits != 4 || stats.Misses != 4 {
+		t.Errorf("expected 4 hits and 4 misses, got %+v", stats)
+	}
+}
+
+func TestCachedRepository_SharesConcurrentMisses(t *testing.T) {
+	next := &countingRepository{post: Post{ID: 1, Title: "Slow"}, release: make(chan struct{})}
+	repo := NewCachedRepository(next, 100, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if post, err := repo.GetPost(context.Background(), 1); err != nil || post.Title != "Slow" {
+				t.Errorf("expected the shared post, got %+v (%v)", post, err)
+			}
+		}()
+	}
+
+	// Let the callers pile up behind the first read before it finishes
+	for next.reads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if reads := next.reads.Load(); reads != 1 {
+		t.Errorf("expected concurrent misses to share one read, got %d", reads)
+	}
+}
+
+func TestCachedRepository_CancelledCallerLeavesLoadRunning(t *testing.T) {
+	next := &countingRepository{post: Post{ID: 1, Title: "Slow"}, release: make(chan struct{})}
+	repo := NewCachedRepository(next, 100, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := repo.GetPost(ctx, 1)
+		done <- err
+	}()
+
+	for next.reads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected the cancelled caller to give up, got %v", err)
+	}
+
+	// The shared read finishes anyway and fills the cache for the next caller
+	close(next.release)
+	for i := 0; i < 100 && repo.Stats().Entries == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if post, err := repo.GetPost(context.Background(), 1); err != nil || post.Title != "Slow" {
+		t.Errorf("expected the post loaded for the cancelled caller, got %+v (%v)", post, err)
+	}
+	if reads := next.reads.Load(); reads != 1 {
+		t.Errorf("expected a single read, got %d", reads)
+	}
+}
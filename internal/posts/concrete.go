@@ -2,7 +2,13 @@ package posts
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
+	"website/internal/search"
+	"website/internal/timeout"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,20 +19,38 @@ type PoolInterface interface {
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+// postFields selects every post column along with the names of the post's tags
+const postFields = "posts.*, ARRAY(SELECT t.name FROM public.post_tags pt JOIN public.tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id ORDER BY t.name) AS tags"
+
+// selectPosts reads posts with their tags
+const selectPosts = "SELECT " + postFields + " FROM public.posts"
+
+// visibleCondition matches posts shown on the public site: published, or scheduled with their publish time reached, and not in the trash
+const visibleCondition = "status IN ('published', 'scheduled') AND publish_at <= NOW() AND deleted_at IS NULL"
+
+// headlineOptions makes ts_headline mark matches the way search.Snippet does
+var headlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d`,
+	search.MarkStart, search.MarkEnd, search.SnippetWords, search.SnippetWords/2)
+
 type ConcreteRepository struct {
-	Pool PoolInterface
+	Pool     PoolInterface
+	Timeouts timeout.Timeouts
 }
 
-func New(pool *pgxpool.Pool) ConcreteRepository {
-	return ConcreteRepository{pool}
+func New(pool *pgxpool.Pool, timeouts timeout.Timeouts) ConcreteRepository {
+	return ConcreteRepository{pool, timeouts}
 }
 
-func (repo ConcreteRepository) GetPost(id int) (*Post, error) {
-	query := "SELECT * FROM public.posts WHERE id = $1"
+func (repo ConcreteRepository) GetPost(ctx context.Context, id int) (*Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := selectPosts + " WHERE id = $1"
 
-	row, err := repo.Pool.Query(context.Background(), query, id)
+	row, err := repo.Pool.Query(ctx, query, id)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting posts: %w", err)
@@ -41,10 +65,38 @@ func (repo ConcreteRepository) GetPost(id int) (*Post, error) {
 	return &post, nil
 }
 
-func (repo ConcreteRepository) GetPosts() ([]Post, error) {
-	query := "SELECT * FROM public.posts ORDER BY created DESC"
+func (repo ConcreteRepository) GetPostBySlug(ctx context.Context, slug string) (*Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := selectPosts + " WHERE slug = $1"
+
+	row, err := repo.Pool.Query(ctx, query, slug)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting post by slug: %w", err)
+	}
+
+	post, err := pgx.CollectOneRow(row, pgx.RowToStructByName[Post])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (repo ConcreteRepository) GetPosts(ctx context.Context) ([]Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
 
-	rows, err := repo.Pool.Query(context.Background(), query)
+	query := selectPosts + " WHERE deleted_at IS NULL ORDER BY created DESC"
+
+	rows, err := repo.Pool.Query(ctx, query)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting posts: %w", err)
@@ -59,20 +111,46 @@ func (repo ConcreteRepository) GetPosts() ([]Post, error) {
 	return posts, nil
 }
 
-func (repo ConcreteRepository) GetPostsPaginated(page int) ([]Post, PaginationInfo, error) {
-	// Get total count first
-	totalPosts, err := repo.GetTotalPostsCount()
+func (repo ConcreteRepository) GetPostsPaginated(ctx context.Context, page int, cursor string) ([]Post, PaginationInfo, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	position, err := parseOptionalCursor(cursor)
+	if err != nil {
+		return nil, PaginationInfo{}, err
+	}
+
+	// Get total count of visible posts first
+	var totalPosts int
+	countQuery := "SELECT COUNT(*) FROM public.posts WHERE " + visibleCondition
+
+	err = repo.Pool.QueryRow(ctx, countQuery).Scan(&totalPosts)
 	if err != nil {
 		return nil, PaginationInfo{}, fmt.Errorf("error getting total posts count: %w", err)
 	}
 
 	// Create pagination info
 	paginationInfo := NewPaginationInfo(totalPosts, page)
-	
-	// Get paginated posts
-	query := "SELECT * FROM public.posts ORDER BY created DESC LIMIT $1 OFFSET $2"
-	
-	rows, err := repo.Pool.Query(context.Background(), query, PostsPerPage, paginationInfo.GetOffset())
+
+	// Get the page by keyset on (created, id), which the posts_created_id_idx index serves without scanning earlier pages
+	var query string
+	var args []interface{}
+	switch {
+	case position == nil:
+		// Page numbers seek to the first post of the page, skipping earlier pages on the index alone
+		query = selectPosts + " WHERE " + visibleCondition + ` AND (created, id) <= (
+			SELECT created, id FROM public.posts WHERE ` + visibleCondition + ` ORDER BY created DESC, id DESC OFFSET $1 LIMIT 1
+		) ORDER BY created DESC, id DESC LIMIT $2`
+		args = []interface{}{paginationInfo.GetOffset(), PostsPerPage}
+	case position.Before:
+		query = selectPosts + " WHERE " + visibleCondition + " AND (created, id) > ($1, $2) ORDER BY created ASC, id ASC LIMIT $3"
+		args = []interface{}{position.Created, position.ID, PostsPerPage}
+	default:
+		query = selectPosts + " WHERE " + visibleCondition + " AND (created, id) < ($1, $2) ORDER BY created DESC, id DESC LIMIT $3"
+		args = []interface{}{position.Created, position.ID, PostsPerPage}
+	}
+
+	rows, err := repo.Pool.Query(ctx, query, args...)
 
 	if err != nil {
 		return nil, PaginationInfo{}, fmt.Errorf("error getting paginated posts: %w", err)
@@ -84,14 +162,23 @@ func (repo ConcreteRepository) GetPostsPaginated(page int) ([]Post, PaginationIn
 		return nil, PaginationInfo{}, fmt.Errorf("error scanning paginated posts: %w", err)
 	}
 
+	// Pages before a cursor are read oldest first
+	if position != nil && position.Before {
+		slices.Reverse(posts)
+	}
+
+	paginationInfo.setCursors(posts)
 	return posts, paginationInfo, nil
 }
 
-func (repo ConcreteRepository) GetTotalPostsCount() (int, error) {
-	query := "SELECT COUNT(*) FROM public.posts"
+func (repo ConcreteRepository) GetTotalPostsCount(ctx context.Context) (int, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := "SELECT COUNT(*) FROM public.posts WHERE deleted_at IS NULL"
 	
 	var count int
-	err := repo.Pool.QueryRow(context.Background(), query).Scan(&count)
+	err := repo.Pool.QueryRow(ctx, query).Scan(&count)
 	
 	if err != nil {
 		return 0, fmt.Errorf("error getting posts count: %w", err)
@@ -100,10 +187,13 @@ func (repo ConcreteRepository) GetTotalPostsCount() (int, error) {
 	return count, nil
 }
 
-func (repo ConcreteRepository) DeletePost(id int) error {
-	query := "DELETE FROM public.posts WHERE id = $1"
+func (repo ConcreteRepository) DeletePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	query := "UPDATE public.posts SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
 	
-	result, err := repo.Pool.Exec(context.Background(), query, id)
+	result, err := repo.Pool.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting post: %w", err)
 	}
@@ -115,31 +205,428 @@ func (repo ConcreteRepository) DeletePost(id int) error {
 	return nil
 }
 
-func (repo ConcreteRepository) UpdatePost(id int, title, description, body string) error {
+func (repo ConcreteRepository) GetDeletedPosts(ctx context.Context) ([]Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := selectPosts + " WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+
+	rows, err := repo.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deleted posts: %w", err)
+	}
+
+	posts, err := pgx.CollectRows[Post](rows, pgx.RowToStructByName[Post])
+	if err != nil {
+		return nil, fmt.Errorf("error scanning deleted posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+func (repo ConcreteRepository) RestorePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	query := "UPDATE public.posts SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+
+	result, err := repo.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error restoring post: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post with id %d not found in trash", id)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) PurgePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	// Tags, revisions and the search entry are removed by cascading deletes
+	query := "DELETE FROM public.posts WHERE id = $1 AND deleted_at IS NOT NULL"
+
+	result, err := repo.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error purging post: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post with id %d not found in trash", id)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) UpdatePost(ctx context.Context, post Post) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	// The post and its tags change together or not at all
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `UPDATE public.posts 
-		SET title = $2, description = $3, body = $4, edited = NOW() 
+		SET title = $2, description = $3, body = $4, author = $5, slug = $6, status = $7, publish_at = $8, trusted = $9, sanitized = COALESCE($10::text[], '{}'), filename = $11, edited = NOW() 
 		WHERE id = $1`
 	
-	result, err := repo.Pool.Exec(context.Background(), query, id, title, description, body)
+	result, err := tx.Exec(ctx, query, post.ID, post.Title, post.Description, post.Body, post.Author, post.Slug, post.Status, post.PublishAt, post.Trusted, post.Sanitized, post.Filename)
 	if err != nil {
 		return fmt.Errorf("error updating post: %w", err)
 	}
 	
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("post with id %d not found", id)
+		return fmt.Errorf("post with id %d not found", post.ID)
 	}
 	
+	if err := setTags(ctx, tx, post.ID, post.Tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing post update: %w", err)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) UpdatePostStatus(ctx context.Context, id int, status Status, publishAt time.Time) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	query := "UPDATE public.posts SET status = $2, publish_at = $3 WHERE id = $1"
+
+	result, err := repo.Pool.Exec(ctx, query, id, status, publishAt)
+	if err != nil {
+		return fmt.Errorf("error updating post status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post with id %d not found", id)
+	}
+
 	return nil
 }
 
-func (repo ConcreteRepository) CreatePost(title, description, body, author string) error {
-	query := `INSERT INTO public.posts (title, description, body, author, created, edited) 
-		VALUES ($1, $2, $3, $4, NOW(), NOW())`
+func (repo ConcreteRepository) UpdatePostBody(ctx context.Context, id int, body, filename string) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	query := "UPDATE public.posts SET body = $2, filename = $3 WHERE id = $1"
+
+	result, err := repo.Pool.Exec(ctx, query, id, body, filename)
+	if err != nil {
+		return fmt.Errorf("error updating post body: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("post with id %d not found", id)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) ReservePostID(ctx context.Context) (int, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	// Sequence values are never handed out twice, even when the transaction that took one rolls back
+	var id int
+	err := repo.Pool.QueryRow(ctx, "SELECT nextval(pg_get_serial_sequence('public.posts', 'id'))").Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error reserving post id: %w", err)
+	}
+
+	return id, nil
+}
+
+func (repo ConcreteRepository) CreatePost(ctx context.Context, post Post) (int, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	// Posts imported with a front matter date keep their original creation time
+	created := post.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	// The post and its tags are stored together or not at all
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Posts without an ID set take the next one from the sequence
+	query := `INSERT INTO public.posts (id, title, description, body, author, slug, status, publish_at, created, trusted, sanitized, filename, edited) 
+		VALUES (COALESCE(NULLIF($12, 0), nextval(pg_get_serial_sequence('public.posts', 'id'))), $1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::text[], '{}'), $11, NOW())
+		RETURNING id`
 	
-	_, err := repo.Pool.Exec(context.Background(), query, title, description, body, author)
+	var id int
+	err = tx.QueryRow(ctx, query, post.Title, post.Description, post.Body, post.Author, post.Slug, post.Status, post.PublishAt, created, post.Trusted, post.Sanitized, post.Filename, post.ID).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("error creating post: %w", err)
+		return 0, fmt.Errorf("error creating post: %w", err)
 	}
 	
+	if err := setTags(ctx, tx, id, post.Tags); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing new post: %w", err)
+	}
+
+	return id, nil
+}
+
+func (repo ConcreteRepository) ImportPost(ctx context.Context, post Post) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	if post.ID <= 0 {
+		return fmt.Errorf("error importing post: id %d is not valid", post.ID)
+	}
+
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO public.posts (id, title, description, body, author, slug, status, publish_at, created, edited, deleted_at, trusted, sanitized, filename)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::text[], '{}'), $14)
+		ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description, body = EXCLUDED.body,
+			author = EXCLUDED.author, slug = EXCLUDED.slug, status = EXCLUDED.status, publish_at = EXCLUDED.publish_at,
+			created = EXCLUDED.created, edited = EXCLUDED.edited, deleted_at = EXCLUDED.deleted_at,
+			trusted = EXCLUDED.trusted, sanitized = EXCLUDED.sanitized, filename = EXCLUDED.filename`
+
+	_, err = tx.Exec(ctx, query, post.ID, post.Title, post.Description, post.Body, post.Author, post.Slug, post.Status, post.PublishAt,
+		post.Created, post.Edited, post.DeletedAt, post.Trusted, post.Sanitized, post.Filename)
+	if err != nil {
+		return fmt.Errorf("error importing post: %w", err)
+	}
+
+	if err := setTags(ctx, tx, post.ID, post.Tags); err != nil {
+		return err
+	}
+
+	// Explicit ids don't advance the sequence, so move it past this one, never back
+	_, err = tx.Exec(ctx, `SELECT setval(pg_get_serial_sequence('public.posts', 'id'), GREATEST(nextval(pg_get_serial_sequence('public.posts', 'id')), $1))`, post.ID)
+	if err != nil {
+		return fmt.Errorf("error advancing post id sequence: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing imported post: %w", err)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) GetPostsByTagPaginated(ctx context.Context, tag string, page int) ([]Post, PaginationInfo, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	tagged := "id IN (SELECT pt.post_id FROM public.post_tags pt JOIN public.tags t ON t.id = pt.tag_id WHERE t.name = $1)"
+
+	// Get total count of visible posts with the tag first
+	var totalPosts int
+	countQuery := "SELECT COUNT(*) FROM public.posts WHERE " + visibleCondition + " AND " + tagged
+
+	err := repo.Pool.QueryRow(ctx, countQuery, tag).Scan(&totalPosts)
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error getting tagged posts count: %w", err)
+	}
+
+	paginationInfo := NewPaginationInfo(totalPosts, page)
+
+	query := selectPosts + " WHERE " + visibleCondition + " AND " + tagged + " ORDER BY created DESC LIMIT $2 OFFSET $3"
+
+	rows, err := repo.Pool.Query(ctx, query, tag, PostsPerPage, paginationInfo.GetOffset())
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error getting tagged posts: %w", err)
+	}
+
+	posts, err := pgx.CollectRows[Post](rows, pgx.RowToStructByName[Post])
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error scanning tagged posts: %w", err)
+	}
+
+	return posts, paginationInfo, nil
+}
+
+func (repo ConcreteRepository) GetTagCounts(ctx context.Context) ([]TagCount, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := `SELECT t.name, COUNT(*) AS count
+		FROM public.tags t
+		JOIN public.post_tags pt ON pt.tag_id = t.id
+		JOIN public.posts ON posts.id = pt.post_id
+		WHERE ` + visibleCondition + `
+		GROUP BY t.name
+		ORDER BY count DESC, t.name`
+
+	rows, err := repo.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tag counts: %w", err)
+	}
+
+	counts, err := pgx.CollectRows[TagCount](rows, pgx.RowToStructByName[TagCount])
+	if err != nil {
+		return nil, fmt.Errorf("error scanning tag counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (repo ConcreteRepository) SearchPosts(ctx context.Context, query string, page int) ([]SearchResult, PaginationInfo, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	// Get total count of visible matches first
+	var totalPosts int
+	countQuery := `SELECT COUNT(*) FROM public.posts
+		JOIN public.post_search s ON s.post_id = posts.id
+		WHERE ` + visibleCondition + ` AND s.document @@ websearch_to_tsquery('english', $1)`
+
+	err := repo.Pool.QueryRow(ctx, countQuery, query).Scan(&totalPosts)
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error getting search results count: %w", err)
+	}
+
+	paginationInfo := NewPaginationInfo(totalPosts, page)
+
+	searchQuery := "SELECT " + postFields + `, ts_rank(s.document, q) AS rank, ts_headline('english', s.body, q, $4) AS snippet
+		FROM public.posts
+		JOIN public.post_search s ON s.post_id = posts.id
+		CROSS JOIN websearch_to_tsquery('english', $1) q
+		WHERE ` + visibleCondition + ` AND s.document @@ q
+		ORDER BY rank DESC, created DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := repo.Pool.Query(ctx, searchQuery, query, PostsPerPage, paginationInfo.GetOffset(), headlineOptions)
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error searching posts: %w", err)
+	}
+
+	results, err := pgx.CollectRows[SearchResult](rows, pgx.RowToStructByName[SearchResult])
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error scanning search results: %w", err)
+	}
+
+	return results, paginationInfo, nil
+}
+
+func (repo ConcreteRepository) IndexPost(ctx context.Context, post Post, text string) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	query := `INSERT INTO public.post_search (post_id, body, document)
+		VALUES ($1, $4, setweight(to_tsvector('english', $2), 'A') || setweight(to_tsvector('english', $3), 'B') || setweight(to_tsvector('english', $4), 'C'))
+		ON CONFLICT (post_id) DO UPDATE SET body = EXCLUDED.body, document = EXCLUDED.document`
+
+	_, err := repo.Pool.Exec(ctx, query, post.ID, post.Title, post.Description, text)
+	if err != nil {
+		return fmt.Errorf("error indexing post: %w", err)
+	}
+
+	return nil
+}
+
+func (repo ConcreteRepository) CreateRevision(ctx context.Context, rev Revision) (Revision, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	if rev.Tags == nil {
+		rev.Tags = []string{}
+	}
+
+	// Number the revision in the same statement that stores it
+	query := `INSERT INTO public.post_revisions (post_id, number, title, description, body, slug, tags, content, note)
+		SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5, $6, $7, $8 FROM public.post_revisions WHERE post_id = $1
+		RETURNING number, created`
+
+	err := repo.Pool.QueryRow(ctx, query, rev.PostID, rev.Title, rev.Description, rev.Body, rev.Slug, rev.Tags, rev.Content, rev.Note).Scan(&rev.Number, &rev.Created)
+	if err != nil {
+		return Revision{}, fmt.Errorf("error creating revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+func (repo ConcreteRepository) GetRevisions(ctx context.Context, postID int) ([]Revision, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := "SELECT * FROM public.post_revisions WHERE post_id = $1 ORDER BY number DESC"
+
+	rows, err := repo.Pool.Query(ctx, query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting revisions: %w", err)
+	}
+
+	revisions, err := pgx.CollectRows[Revision](rows, pgx.RowToStructByName[Revision])
+	if err != nil {
+		return nil, fmt.Errorf("error scanning revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (repo ConcreteRepository) GetRevision(ctx context.Context, postID, number int) (*Revision, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	query := "SELECT * FROM public.post_revisions WHERE post_id = $1 AND number = $2"
+
+	rows, err := repo.Pool.Query(ctx, query, postID, number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting revision: %w", err)
+	}
+
+	rev, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Revision])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// setTags replaces the tags of a post in a single statement, creating tag rows as needed.
+// It runs on the transaction the post itself is written in.
+func setTags(ctx context.Context, db PoolInterface, id int, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	query := `WITH removed AS (
+			DELETE FROM public.post_tags
+			WHERE post_id = $1 AND tag_id NOT IN (SELECT id FROM public.tags WHERE name = ANY($2::text[]))
+		), added AS (
+			INSERT INTO public.tags (name) SELECT unnest($2::text[])
+			ON CONFLICT (name) DO NOTHING
+			RETURNING id
+		)
+		INSERT INTO public.post_tags (post_id, tag_id)
+		SELECT $1, id FROM added
+		UNION
+		SELECT $1, id FROM public.tags WHERE name = ANY($2::text[])
+		ON CONFLICT DO NOTHING`
+
+	_, err := db.Exec(ctx, query, id, tags)
+	if err != nil {
+		return fmt.Errorf("error setting post tags: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,80 @@
+package posts
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for cursor tokens that were not issued by PaginationInfo
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in the public post list, which is ordered newest first by creation time and then by ID.
+// A page fetched with a cursor starts right after the marked post, or ends right before it when Before is set.
+type Cursor struct {
+	Created time.Time
+	ID      int
+	Before  bool
+}
+
+// cursorVersion prefixes tokens so their format can change without misreading old links
+const cursorVersion = "1"
+
+// PostCursor returns a cursor at the given post
+func PostCursor(post Post, before bool) Cursor {
+	return Cursor{Created: post.Created, ID: post.ID, Before: before}
+}
+
+// Token encodes the cursor as an opaque string safe to use in URLs
+func (c Cursor) Token() string {
+	direction := "a"
+	if c.Before {
+		direction = "b"
+	}
+
+	raw := fmt.Sprintf("%s:%s:%d:%d", cursorVersion, direction, c.Created.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a token created by Token
+func ParseCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 || parts[0] != cursorVersion || (parts[1] != "a" && parts[1] != "b") {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	id, err := strconv.Atoi(parts[3])
+	if err != nil || id < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	// Timestamps are read back from the database in UTC, so comparisons must use UTC too
+	return Cursor{Created: time.Unix(0, nanos).UTC(), ID: id, Before: parts[1] == "b"}, nil
+}
+
+// Helper function to parse an optional cursor token, returning nil for an empty one
+func parseOptionalCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	cursor, err := ParseCursor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,43 @@
+package posts
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorToken(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC)
+
+	for _, cursor := range []Cursor{
+		{Created: created, ID: 42},
+		{Created: created, ID: 7, Before: true},
+	} {
+		parsed, err := ParseCursor(cursor.Token())
+		if err != nil {
+			t.Fatalf("expected %+v to round-trip, got %v", cursor, err)
+		}
+		if !parsed.Created.Equal(cursor.Created) || parsed.ID != cursor.ID || parsed.Before != cursor.Before {
+			t.Errorf("expected %+v, got %+v", cursor, parsed)
+		}
+	}
+}
+
+func TestParseCursor_Invalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	for _, token := range []string{
+		"",
+		"!!!",
+		encode("1:a:123"),
+		encode("2:a:123:4"),
+		encode("1:x:123:4"),
+		encode("1:a:soon:4"),
+		encode("1:a:123:-4"),
+	} {
+		if _, err := ParseCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected %q to be rejected, got %v", token, err)
+		}
+	}
+}
@@ -4,28 +4,70 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
+	"website/internal/search"
+	"website/internal/timeout"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// countersCollection holds one document per collection counting the ids handed out to its documents
+const countersCollection = "counters"
+
+// errPostMissing stops a transaction that finds the post it changes gone
+var errPostMissing = errors.New("post not found")
+
+// visibleCountTTL bounds how long a counted number of visible posts is trusted, since posts written by other
+// instances don't reset this instance's count
+const visibleCountTTL = time.Minute
+
 type FirestoreRepository struct {
 	Client     *firestore.Client
 	Collection string
+	Timeouts   timeout.Timeouts
+
+	// Firestore has no full-text search, so IndexPost feeds an in-memory inverted index instead.
+	// The index only covers this process and is rebuilt by reindexing every post at startup.
+	index *search.Index
+
+	// Posts stored before the trash existed keep an aggregation query from counting visible posts, so the
+	// count is kept from the last time the posts were read, until a write, a scheduled post or visibleCountTTL
+	count visibleCount
 }
 
-func NewFirestoreRepository(client *firestore.Client) *FirestoreRepository {
+// visibleCount is a remembered number of posts visible on the public site
+type visibleCount struct {
+	mu      sync.Mutex
+	posts   int
+	expires time.Time
+}
+
+// reset forgets the count after a write that may have changed it
+func (c *visibleCount) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires = time.Time{}
+}
+
+func NewFirestoreRepository(client *firestore.Client, timeouts timeout.Timeouts) *FirestoreRepository {
 	return &FirestoreRepository{
 		Client:     client,
 		Collection: "posts",
+		Timeouts:   timeouts,
+		index:      search.NewIndex(),
 	}
 }
 
-func (repo *FirestoreRepository) GetPost(id int) (*Post, error) {
-	ctx := context.Background()
+func (repo *FirestoreRepository) GetPost(ctx context.Context, id int) (*Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
 	doc, err := repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(id)).Get(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error getting post: %w", err)
@@ -40,8 +82,79 @@ func (repo *FirestoreRepository) GetPost(id int) (*Post, error) {
 	return &post, nil
 }
 
-func (repo *FirestoreRepository) GetPosts() ([]Post, error) {
-	ctx := context.Background()
+func (repo *FirestoreRepository) GetPostBySlug(ctx context.Context, slug string) (*Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+	iter := repo.Client.Collection(repo.Collection).Where("slug", "==", slug).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if errors.Is(err, iterator.Done) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting post by slug: %w", err)
+	}
+
+	var post Post
+	if err := doc.DataTo(&post); err != nil {
+		return nil, fmt.Errorf("error unmarshaling post: %w", err)
+	}
+
+	// Parse ID from document ID
+	if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+		post.ID = id
+	}
+
+	return &post, nil
+}
+
+func (repo *FirestoreRepository) GetPosts(ctx context.Context) ([]Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	all, err := repo.getAllPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []Post
+	for _, post := range all {
+		if post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
+	}
+
+	return posts, nil
+}
+
+func (repo *FirestoreRepository) GetDeletedPosts(ctx context.Context) ([]Post, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+
+	all, err := repo.getAllPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []Post
+	for _, post := range all {
+		if post.DeletedAt != nil {
+			deleted = append(deleted, post)
+		}
+	}
+
+	// Most recently deleted first
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].DeletedAt.After(*deleted[j].DeletedAt)
+	})
+
+	return deleted, nil
+}
+
+// getAllPosts reads every post document, including those in the trash, newest first.
+// Posts stored before the trash existed have no deleted_at field, so deleted posts can't be filtered in the query.
+func (repo *FirestoreRepository) getAllPosts(ctx context.Context) ([]Post, error) {
 	iter := repo.Client.Collection(repo.Collection).Documents(ctx)
 	defer iter.Stop()
 
@@ -76,14 +189,220 @@ func (repo *FirestoreRepository) GetPosts() ([]Post, error) {
 	return posts, nil
 }
 
-func (repo *FirestoreRepository) GetPostsPaginated(page int) ([]Post, PaginationInfo, error) {
-	// Get all posts first to calculate pagination
-	allPosts, err := repo.GetPosts()
+func (repo *FirestoreRepository) GetPostsPaginated(ctx context.Context, page int, cursor string) ([]Post, PaginationInfo, error) {
+	position, err := parseOptionalCursor(cursor)
+	if err != nil {
+		return nil, PaginationInfo{}, err
+	}
+
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+	now := time.Now()
+
+	total, err := repo.visiblePostCount(ctx, now)
+	if err != nil {
+		return nil, PaginationInfo{}, fmt.Errorf("error counting posts for pagination: %w", err)
+	}
+
+	paginationInfo := NewPaginationInfo(total, page)
+
+	// Without a cursor, the page starts right after the last post of the page before it, which is found by
+	// reading keys only as far as that post
+	if position == nil && paginationInfo.CurrentPage > 1 {
+		offset := paginationInfo.GetOffset()
+		keys, err := repo.visiblePostKeys(ctx, now, offset)
+		if err != nil {
+			return nil, PaginationInfo{}, fmt.Errorf("error finding page start: %w", err)
+		}
+		if len(keys) < offset {
+			// Fewer posts are visible than counted, so the page is past the end
+			return nil, paginationInfo, nil
+		}
+		position = &keys[offset-1]
+	}
+
+	paginatedPosts, err := repo.visiblePostsAfter(ctx, position, now, PostsPerPage)
 	if err != nil {
-		return nil, PaginationInfo{}, fmt.Errorf("error getting posts for pagination: %w", err)
+		return nil, PaginationInfo{}, fmt.Errorf("error getting paginated posts: %w", err)
 	}
 
-	totalPosts := len(allPosts)
+	paginationInfo.setCursors(paginatedPosts)
+	return paginatedPosts, paginationInfo, nil
+}
+
+// listOrder orders posts the way the public list does: newest first, then by document ID
+func (repo *FirestoreRepository) listOrder(direction firestore.Direction) firestore.Query {
+	return repo.Client.Collection(repo.Collection).OrderBy("created", direction).OrderBy(firestore.DocumentID, direction)
+}
+
+// visiblePostCount returns how many posts are visible on the public site, counting them by reading only the
+// fields that decide visibility when the remembered count has expired. The count expires early when a scheduled
+// post is due to be published.
+func (repo *FirestoreRepository) visiblePostCount(ctx context.Context, now time.Time) (int, error) {
+	repo.count.mu.Lock()
+	defer repo.count.mu.Unlock()
+
+	if now.Before(repo.count.expires) {
+		return repo.count.posts, nil
+	}
+
+	iter := repo.listOrder(firestore.Desc).Select("status", "publish_at", "deleted_at").Documents(ctx)
+	defer iter.Stop()
+
+	count := 0
+	expires := now.Add(visibleCountTTL)
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error iterating posts: %w", err)
+		}
+
+		post, err := postFromDoc(doc)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case post.IsVisibleAt(now):
+			count++
+		case post.DeletedAt == nil && post.StatusAt(now) == StatusScheduled && post.PublishAt.Before(expires):
+			expires = post.PublishAt
+		}
+	}
+
+	repo.count.posts, repo.count.expires = count, expires
+	return count, nil
+}
+
+// visiblePostKeys returns a cursor at each of the first limit posts visible on the public site, in list order
+func (repo *FirestoreRepository) visiblePostKeys(ctx context.Context, now time.Time, limit int) ([]Cursor, error) {
+	iter := repo.listOrder(firestore.Desc).Select("created", "status", "publish_at", "deleted_at").Documents(ctx)
+	defer iter.Stop()
+
+	var keys []Cursor
+	for len(keys) < limit {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error iterating posts: %w", err)
+		}
+
+		post, err := postFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if post.IsVisibleAt(now) {
+			keys = append(keys, PostCursor(post, false))
+		}
+	}
+
+	return keys, nil
+}
+
+// visiblePostsAfter reads up to limit posts visible on the public site that come after the cursor in list order,
+// or before it for cursors with Before set. Hidden posts can only be skipped once read, so documents are read
+// in batches until the page is full.
+func (repo *FirestoreRepository) visiblePostsAfter(ctx context.Context, position *Cursor, now time.Time, limit int) ([]Post, error) {
+	direction := firestore.Desc
+	if position != nil && position.Before {
+		direction = firestore.Asc
+	}
+
+	query := repo.listOrder(direction)
+	if position != nil {
+		query = query.StartAfter(position.Created, strconv.Itoa(position.ID))
+	}
+
+	var posts []Post
+	for len(posts) < limit {
+		docs, err := query.Limit(limit).Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating posts: %w", err)
+		}
+
+		for _, doc := range docs {
+			post, err := postFromDoc(doc)
+			if err != nil {
+				return nil, err
+			}
+
+			if post.IsVisibleAt(now) && len(posts) < limit {
+				posts = append(posts, post)
+			}
+		}
+
+		if len(docs) < limit {
+			break
+		}
+		query = query.StartAfter(docs[len(docs)-1])
+	}
+
+	// Pages before a cursor are read oldest first
+	if direction == firestore.Asc {
+		slices.Reverse(posts)
+	}
+
+	return posts, nil
+}
+
+// postFromDoc unmarshals a post document, taking the post's ID from the document ID
+func postFromDoc(doc *firestore.DocumentSnapshot) (Post, error) {
+	var post Post
+	if err := doc.DataTo(&post); err != nil {
+		return Post{}, fmt.Errorf("error unmarshaling post: %w", err)
+	}
+
+	if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+		post.ID = id
+	}
+
+	return post, nil
+}
+
+func (repo *FirestoreRepository) GetPostsByTagPaginated(ctx context.Context, tag string, page int) ([]Post, PaginationInfo, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+	iter := repo.Client.Collection(repo.Collection).Where("tags", "array-contains", tag).Documents(ctx)
+	defer iter.Stop()
+
+	now := time.Now()
+	var tagged []Post
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, PaginationInfo{}, fmt.Errorf("error iterating tagged posts: %w", err)
+		}
+
+		var post Post
+		if err := doc.DataTo(&post); err != nil {
+			return nil, PaginationInfo{}, fmt.Errorf("error unmarshaling post: %w", err)
+		}
+
+		// Parse ID from document ID
+		if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+			post.ID = id
+		}
+
+		if post.IsVisibleAt(now) {
+			tagged = append(tagged, post)
+		}
+	}
+
+	// Sort by created date descending
+	sort.Slice(tagged, func(i, j int) bool {
+		return tagged[i].Created.After(tagged[j].Created)
+	})
+
+	totalPosts := len(tagged)
 	paginationInfo := NewPaginationInfo(totalPosts, page)
 
 	// Calculate slice bounds
@@ -95,39 +414,173 @@ func (repo *FirestoreRepository) GetPostsPaginated(page int) ([]Post, Pagination
 
 	var paginatedPosts []Post
 	if offset < totalPosts {
-		paginatedPosts = allPosts[offset:end]
+		paginatedPosts = tagged[offset:end]
 	}
 
 	return paginatedPosts, paginationInfo, nil
 }
 
-func (repo *FirestoreRepository) GetTotalPostsCount() (int, error) {
-	ctx := context.Background()
+func (repo *FirestoreRepository) GetTagCounts(ctx context.Context) ([]TagCount, error) {
+	allPosts, err := repo.GetPosts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting posts for tag counts: %w", err)
+	}
 
-	// Use Firestore aggregation query for efficient counting
-	query := repo.Client.Collection(repo.Collection)
-	countQuery := query.NewAggregationQuery().WithCount("total")
+	now := time.Now()
+	counts := make(map[string]int)
+	for _, post := range allPosts {
+		if !post.IsVisibleAt(now) {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+
+	tagCounts := make([]TagCount, 0, len(counts))
+	for name, count := range counts {
+		tagCounts = append(tagCounts, TagCount{Name: name, Count: count})
+	}
+
+	// Most used first, then alphabetical
+	sort.Slice(tagCounts, func(i, j int) bool {
+		if tagCounts[i].Count != tagCounts[j].Count {
+			return tagCounts[i].Count > tagCounts[j].Count
+		}
+		return tagCounts[i].Name < tagCounts[j].Name
+	})
+
+	return tagCounts, nil
+}
 
-	results, err := countQuery.Get(ctx)
+func (repo *FirestoreRepository) GetTotalPostsCount(ctx context.Context) (int, error) {
+	// An aggregation query can't skip trashed posts without matching documents missing deleted_at as well
+	posts, err := repo.GetPosts(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("error getting post count: %w", err)
 	}
 
-	countResult, ok := results["total"]
-	if !ok {
-		return 0, fmt.Errorf("count result not found")
+	return len(posts), nil
+}
+
+func (repo *FirestoreRepository) DeletePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+	docID := strconv.Itoa(id)
+
+	// Check if document exists first
+	post, err := repo.GetPost(ctx, id)
+	if err != nil || post.DeletedAt != nil {
+		return fmt.Errorf("post with id %d not found", id)
+	}
+
+	_, err = repo.Client.Collection(repo.Collection).Doc(docID).Update(ctx, []firestore.Update{
+		{Path: "deleted_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting post: %w", err)
+	}
+
+	repo.index.Remove(id)
+	repo.count.reset()
+
+	return nil
+}
+
+func (repo *FirestoreRepository) RestorePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	post, err := repo.GetPost(ctx, id)
+	if err != nil || post.DeletedAt == nil {
+		return fmt.Errorf("post with id %d not found in trash", id)
+	}
+
+	_, err = repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(id)).Update(ctx, []firestore.Update{
+		{Path: "deleted_at", Value: nil},
+	})
+	if err != nil {
+		return fmt.Errorf("error restoring post: %w", err)
+	}
+
+	repo.count.reset()
+
+	return nil
+}
+
+func (repo *FirestoreRepository) PurgePost(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	post, err := repo.GetPost(ctx, id)
+	if err != nil || post.DeletedAt == nil {
+		return fmt.Errorf("post with id %d not found in trash", id)
+	}
+
+	// Firestore keeps subcollections of deleted documents, so remove the post's revisions explicitly
+	refs, err := repo.revisions(id).DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("error listing revisions of purged post: %w", err)
+	}
+	for _, ref := range refs {
+		if _, err := ref.Delete(ctx); err != nil {
+			return fmt.Errorf("error deleting revision: %w", err)
+		}
+	}
+
+	_, err = repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(id)).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("error purging post: %w", err)
+	}
+
+	repo.index.Remove(id)
+	repo.count.reset()
+
+	return nil
+}
+
+func (repo *FirestoreRepository) UpdatePost(ctx context.Context, post Post) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+	ref := repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(post.ID))
+
+	updates := []firestore.Update{
+		{Path: "title", Value: post.Title},
+		{Path: "description", Value: post.Description},
+		{Path: "body", Value: post.Body},
+		{Path: "author", Value: post.Author},
+		{Path: "slug", Value: post.Slug},
+		{Path: "status", Value: post.Status},
+		{Path: "publish_at", Value: post.PublishAt},
+		{Path: "tags", Value: post.Tags},
+		{Path: "trusted", Value: post.Trusted},
+		{Path: "sanitized", Value: post.Sanitized},
+		{Path: "filename", Value: post.Filename},
+		{Path: "edited", Value: time.Now()},
 	}
 
-	countValue, ok := countResult.(int64)
-	if !ok {
-		return 0, fmt.Errorf("unexpected count type: %T", countResult)
+	// Checking that the post exists and updating it in one transaction keeps a concurrent purge from being undone
+	err := repo.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			return errPostMissing
+		}
+		return tx.Update(ref, updates)
+	})
+	if errors.Is(err, errPostMissing) {
+		return fmt.Errorf("post with id %d not found", post.ID)
 	}
+	if err != nil {
+		return fmt.Errorf("error updating post: %w", err)
+	}
+
+	repo.count.reset()
 
-	return int(countValue), nil
+	return nil
 }
 
-func (repo *FirestoreRepository) DeletePost(id int) error {
-	ctx := context.Background()
+func (repo *FirestoreRepository) UpdatePostStatus(ctx context.Context, id int, status Status, publishAt time.Time) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
 	docID := strconv.Itoa(id)
 
 	// Check if document exists first
@@ -136,86 +589,345 @@ func (repo *FirestoreRepository) DeletePost(id int) error {
 		return fmt.Errorf("post with id %d not found", id)
 	}
 
-	_, err = repo.Client.Collection(repo.Collection).Doc(docID).Delete(ctx)
+	updates := []firestore.Update{
+		{Path: "status", Value: status},
+		{Path: "publish_at", Value: publishAt},
+	}
+
+	_, err = repo.Client.Collection(repo.Collection).Doc(docID).Update(ctx, updates)
 	if err != nil {
-		return fmt.Errorf("error deleting post: %w", err)
+		return fmt.Errorf("error updating post status: %w", err)
 	}
 
+	repo.count.reset()
+
 	return nil
 }
 
-func (repo *FirestoreRepository) UpdatePost(id int, title, description, body string) error {
-	ctx := context.Background()
+func (repo *FirestoreRepository) UpdatePostBody(ctx context.Context, id int, body, filename string) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
 	docID := strconv.Itoa(id)
 
 	// Check if document exists first
-	doc, err := repo.Client.Collection(repo.Collection).Doc(docID).Get(ctx)
+	_, err := repo.Client.Collection(repo.Collection).Doc(docID).Get(ctx)
 	if err != nil {
 		return fmt.Errorf("post with id %d not found", id)
 	}
 
-	var existingPost Post
-	if err := doc.DataTo(&existingPost); err != nil {
-		return fmt.Errorf("error reading existing post: %w", err)
-	}
-
 	updates := []firestore.Update{
-		{Path: "title", Value: title},
-		{Path: "description", Value: description},
 		{Path: "body", Value: body},
-		{Path: "edited", Value: time.Now()},
+		{Path: "filename", Value: filename},
 	}
 
 	_, err = repo.Client.Collection(repo.Collection).Doc(docID).Update(ctx, updates)
 	if err != nil {
-		return fmt.Errorf("error updating post: %w", err)
+		return fmt.Errorf("error updating post body: %w", err)
 	}
 
 	return nil
 }
 
-func (repo *FirestoreRepository) CreatePost(title, description, body, author string) error {
-	ctx := context.Background()
+func (repo *FirestoreRepository) ReservePostID(ctx context.Context) (int, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
 
-	// Get next available ID
-	nextID, err := repo.getNextID()
+	var id int
+	err := repo.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		next, err := repo.nextID(tx)
+		if err != nil {
+			return err
+		}
+
+		id = next
+		return tx.Set(repo.counter(), map[string]interface{}{"next": next + 1})
+	})
 	if err != nil {
-		return fmt.Errorf("error getting next ID: %w", err)
+		return 0, fmt.Errorf("error reserving post id: %w", err)
 	}
 
+	return id, nil
+}
+
+func (repo *FirestoreRepository) CreatePost(ctx context.Context, post Post) (int, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
 	now := time.Now()
-	post := map[string]interface{}{
-		"title":       title,
-		"description": description,
-		"body":        body,
-		"author":      author,
-		"created":     now,
+
+	// Posts imported with a front matter date keep their original creation time
+	created := post.Created
+	if created.IsZero() {
+		created = now
+	}
+
+	data := map[string]interface{}{
+		"title":       post.Title,
+		"description": post.Description,
+		"body":        post.Body,
+		"author":      post.Author,
+		"slug":        post.Slug,
+		"status":      post.Status,
+		"publish_at":  post.PublishAt,
+		"tags":        post.Tags,
+		"trusted":     post.Trusted,
+		"sanitized":   post.Sanitized,
+		"filename":    post.Filename,
+		"created":     created,
 		"edited":      now,
 	}
 
-	_, err = repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(nextID)).Set(ctx, post)
+	// Taking an id from the counter and creating the post commit together, so concurrent creates can't share an id
+	var id int
+	err := repo.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		next, err := repo.nextID(tx)
+		if err != nil {
+			return err
+		}
+
+		id = post.ID
+		if id == 0 {
+			id = next
+		}
+
+		// Posts stored under an id of their own move the counter past it
+		if id >= next {
+			if err := tx.Set(repo.counter(), map[string]interface{}{"next": id + 1}); err != nil {
+				return err
+			}
+		}
+
+		// Create fails rather than overwriting if a post already has the id
+		return tx.Create(repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(id)), data)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error creating post: %w", err)
+	}
+
+	repo.count.reset()
+
+	return id, nil
+}
+
+func (repo *FirestoreRepository) ImportPost(ctx context.Context, post Post) error {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+
+	if post.ID <= 0 {
+		return fmt.Errorf("error importing post: id %d is not valid", post.ID)
+	}
+
+	data := map[string]interface{}{
+		"title":       post.Title,
+		"description": post.Description,
+		"body":        post.Body,
+		"author":      post.Author,
+		"slug":        post.Slug,
+		"status":      post.Status,
+		"publish_at":  post.PublishAt,
+		"tags":        post.Tags,
+		"trusted":     post.Trusted,
+		"sanitized":   post.Sanitized,
+		"filename":    post.Filename,
+		"created":     post.Created,
+		"edited":      post.Edited,
+		"deleted_at":  post.DeletedAt,
+	}
+
+	err := repo.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		next, err := repo.nextID(tx)
+		if err != nil {
+			return err
+		}
+
+		if post.ID >= next {
+			if err := tx.Set(repo.counter(), map[string]interface{}{"next": post.ID + 1}); err != nil {
+				return err
+			}
+		}
+
+		return tx.Set(repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(post.ID)), data)
+	})
 	if err != nil {
-		return fmt.Errorf("error creating post: %w", err)
+		return fmt.Errorf("error importing post: %w", err)
 	}
 
+	repo.count.reset()
+
 	return nil
 }
 
-func (repo *FirestoreRepository) getNextID() (int, error) {
-	ctx := context.Background()
-	iter := repo.Client.Collection(repo.Collection).Documents(ctx)
+func (repo *FirestoreRepository) SearchPosts(ctx context.Context, query string, page int) ([]SearchResult, PaginationInfo, error) {
+	hits := repo.index.Search(query)
+
+	// Look up the matching posts, keeping only those visible on the public site in rank order
+	var matches []SearchResult
+	if len(hits) > 0 {
+		allPosts, err := repo.GetPosts(ctx)
+		if err != nil {
+			return nil, PaginationInfo{}, fmt.Errorf("error getting posts for search: %w", err)
+		}
+
+		byID := make(map[int]Post, len(allPosts))
+		for _, post := range allPosts {
+			byID[post.ID] = post
+		}
+
+		now := time.Now()
+		for _, hit := range hits {
+			post, ok := byID[hit.ID]
+			if ok && post.IsVisibleAt(now) {
+				matches = append(matches, SearchResult{Post: post, Rank: hit.Score})
+			}
+		}
+	}
+
+	totalPosts := len(matches)
+	paginationInfo := NewPaginationInfo(totalPosts, page)
+
+	// Calculate slice bounds
+	offset := paginationInfo.GetOffset()
+	end := offset + PostsPerPage
+	if end > totalPosts {
+		end = totalPosts
+	}
+
+	var results []SearchResult
+	if offset < totalPosts {
+		results = matches[offset:end]
+	}
+
+	// Only the shown page needs snippets
+	for i := range results {
+		results[i].Snippet = repo.index.Snippet(results[i].ID, query)
+	}
+
+	return results, paginationInfo, nil
+}
+
+func (repo *FirestoreRepository) IndexPost(ctx context.Context, post Post, text string) error {
+	repo.index.Add(post.ID, search.Fields{
+		Title:       post.Title,
+		Description: post.Description,
+		Body:        text,
+	})
+
+	return nil
+}
+
+func (repo *FirestoreRepository) CreateRevision(ctx context.Context, rev Revision) (Revision, error) {
+	ctx, cancel := repo.Timeouts.ForWrite(ctx)
+	defer cancel()
+	revisions := repo.revisions(rev.PostID)
+
+	// Number the revision after the post's latest one
+	iter := revisions.OrderBy("number", firestore.Desc).Limit(1).Documents(ctx)
 	defer iter.Stop()
 
-	maxID := 0
+	rev.Number = 1
+	doc, err := iter.Next()
+	if err != nil && !errors.Is(err, iterator.Done) {
+		return Revision{}, fmt.Errorf("error getting latest revision: %w", err)
+	}
+	if err == nil {
+		var latest Revision
+		if err := doc.DataTo(&latest); err != nil {
+			return Revision{}, fmt.Errorf("error unmarshaling revision: %w", err)
+		}
+		rev.Number = latest.Number + 1
+	}
+
+	rev.Created = time.Now()
+
+	// Create fails rather than overwriting if another save claimed the number first
+	_, err = revisions.Doc(strconv.Itoa(rev.Number)).Create(ctx, rev)
+	if err != nil {
+		return Revision{}, fmt.Errorf("error creating revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+func (repo *FirestoreRepository) GetRevisions(ctx context.Context, postID int) ([]Revision, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+	iter := repo.revisions(postID).OrderBy("number", firestore.Desc).Documents(ctx)
+	defer iter.Stop()
+
+	var revisions []Revision
 	for {
 		doc, err := iter.Next()
 		if errors.Is(err, iterator.Done) {
 			break
 		}
 		if err != nil {
-			return 0, fmt.Errorf("error iterating documents: %w", err)
+			return nil, fmt.Errorf("error iterating revisions: %w", err)
 		}
 
+		var rev Revision
+		if err := doc.DataTo(&rev); err != nil {
+			return nil, fmt.Errorf("error unmarshaling revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, nil
+}
+
+func (repo *FirestoreRepository) GetRevision(ctx context.Context, postID, number int) (*Revision, error) {
+	ctx, cancel := repo.Timeouts.ForRead(ctx)
+	defer cancel()
+	doc, err := repo.revisions(postID).Doc(strconv.Itoa(number)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting revision: %w", err)
+	}
+
+	var rev Revision
+	if err := doc.DataTo(&rev); err != nil {
+		return nil, fmt.Errorf("error unmarshaling revision: %w", err)
+	}
+
+	return &rev, nil
+}
+
+// revisions is the subcollection holding a post's revisions, keyed by revision number
+func (repo *FirestoreRepository) revisions(postID int) *firestore.CollectionRef {
+	return repo.Client.Collection(repo.Collection).Doc(strconv.Itoa(postID)).Collection("revisions")
+}
+
+// counter is the document holding the next post id to hand out
+func (repo *FirestoreRepository) counter() *firestore.DocumentRef {
+	return repo.Client.Collection(countersCollection).Doc(repo.Collection)
+}
+
+// nextID reads the next post id from the counter within a transaction. Until the counter exists, it is worked out
+// from the highest id among the posts, which the transaction also reads so a post created meanwhile is not missed.
+func (repo *FirestoreRepository) nextID(tx *firestore.Transaction) (int, error) {
+	doc, err := tx.Get(repo.counter())
+	if err == nil {
+		next, err := doc.DataAt("next")
+		if err != nil {
+			return 0, fmt.Errorf("error reading post id counter: %w", err)
+		}
+		if n, ok := next.(int64); ok {
+			return int(n), nil
+		}
+		return 0, fmt.Errorf("post id counter holds %T rather than a number", next)
+	}
+	if status.Code(err) != codes.NotFound {
+		return 0, fmt.Errorf("error getting post id counter: %w", err)
+	}
+
+	docs, err := tx.Documents(repo.Client.Collection(repo.Collection).Select()).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	maxID := 0
+	for _, doc := range docs {
 		if id, err := strconv.Atoi(doc.Ref.ID); err == nil && id > maxID {
 			maxID = id
 		}
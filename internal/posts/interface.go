@@ -1,11 +1,56 @@
 package posts
 
+import (
+	"context"
+	"time"
+)
+
+// Repository stores posts and their revisions. Every method takes the context of the request or job it runs for,
+// and backends give up once it is cancelled.
 type Repository interface {
-	GetPost(id int) (*Post, error)
-	GetPosts() ([]Post, error)
-	GetPostsPaginated(page int) ([]Post, PaginationInfo, error)
-	GetTotalPostsCount() (int, error)
-	DeletePost(id int) error
-	UpdatePost(id int, title, description, body string) error
-	CreatePost(title, description, body, author string) error
+	GetPost(ctx context.Context, id int) (*Post, error)
+	GetPostBySlug(ctx context.Context, slug string) (*Post, error)
+	// GetPosts returns every post outside the trash regardless of status, newest first
+	GetPosts(ctx context.Context) ([]Post, error)
+	// GetPostsPaginated returns a page of posts visible on the public site, newest first. A cursor token from the
+	// PaginationInfo of a neighbouring page fetches the page by keyset; without one the page is found by its number.
+	// Returns ErrInvalidCursor for tokens it did not issue.
+	GetPostsPaginated(ctx context.Context, page int, cursor string) ([]Post, PaginationInfo, error)
+	// GetPostsByTagPaginated returns a page of public posts carrying the tag
+	GetPostsByTagPaginated(ctx context.Context, tag string, page int) ([]Post, PaginationInfo, error)
+	// GetTagCounts returns every tag used by public posts with its post count, most used first
+	GetTagCounts(ctx context.Context) ([]TagCount, error)
+	// SearchPosts returns a page of public posts matching the full-text query, best matches first
+	SearchPosts(ctx context.Context, query string, page int) ([]SearchResult, PaginationInfo, error)
+	// IndexPost stores the searchable text of a post, replacing what was indexed before
+	IndexPost(ctx context.Context, post Post, text string) error
+	GetTotalPostsCount(ctx context.Context) (int, error)
+	// DeletePost moves a post to the trash, hiding it everywhere but the trash view
+	DeletePost(ctx context.Context, id int) error
+	// GetDeletedPosts returns the posts in the trash, most recently deleted first
+	GetDeletedPosts(ctx context.Context) ([]Post, error)
+	// RestorePost takes a post out of the trash
+	RestorePost(ctx context.Context, id int) error
+	// PurgePost permanently removes a post in the trash along with its tags, revisions and search entry
+	PurgePost(ctx context.Context, id int) error
+	// UpdatePost replaces a post's details and tags together
+	UpdatePost(ctx context.Context, post Post) error
+	UpdatePostStatus(ctx context.Context, id int, status Status, publishAt time.Time) error
+	// UpdatePostBody points a post at another content file without changing its edited time
+	UpdatePostBody(ctx context.Context, id int, body, filename string) error
+	// ReservePostID hands out an id no other post has or will be given, so content can be stored under a
+	// post's id before the post itself is created with it
+	ReservePostID(ctx context.Context) (int, error)
+	// CreatePost stores a new post with its tags and returns its id. Posts with an id set, such as one from
+	// ReservePostID, are stored under it; creating a post whose id is taken fails.
+	CreatePost(ctx context.Context, post Post) (int, error)
+	// ImportPost stores a post copied from another backend exactly as given, including its id, timestamps, tags and
+	// trash state, replacing any post already stored under the id. Later posts are never given an imported id.
+	ImportPost(ctx context.Context, post Post) error
+	// CreateRevision stores a revision of a post as the one after its latest, returning it with its number and time set
+	CreateRevision(ctx context.Context, rev Revision) (Revision, error)
+	// GetRevisions returns every revision of a post, newest first
+	GetRevisions(ctx context.Context, postID int) ([]Revision, error)
+	// GetRevision returns a single revision of a post, or ErrRevisionNotFound
+	GetRevision(ctx context.Context, postID, number int) (*Revision, error)
 }
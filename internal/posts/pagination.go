@@ -11,6 +11,10 @@ type PaginationInfo struct {
 	HasPrev     bool
 	NextPage    int
 	PrevPage    int
+	// NextCursor and PrevCursor are opaque tokens fetching the neighbouring pages by keyset rather than by
+	// page number; they are empty for lists that are not paginated by cursor
+	NextCursor string
+	PrevCursor string
 }
 
 // NewPaginationInfo creates pagination info from total posts and current page
@@ -55,4 +59,18 @@ func NewPaginationInfo(totalPosts, currentPage int) PaginationInfo {
 // GetOffset calculates the SQL OFFSET for the current page
 func (p PaginationInfo) GetOffset() int {
 	return (p.CurrentPage - 1) * PostsPerPage
-}
\ No newline at end of file
+}
+
+// Helper function to set the cursors of the pages around a page of posts in list order
+func (p *PaginationInfo) setCursors(posts []Post) {
+	if len(posts) == 0 {
+		return
+	}
+
+	if p.HasNext {
+		p.NextCursor = PostCursor(posts[len(posts)-1], false).Token()
+	}
+	if p.HasPrev {
+		p.PrevCursor = PostCursor(posts[0], true).Token()
+	}
+}
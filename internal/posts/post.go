@@ -1,13 +1,36 @@
 package posts
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type Post struct {
-	ID      int       `db:"id"`
-	Title   string    `db:"title"`
-	Author  string    `db:"author"`
-	Created time.Time `db:"created"`
-	Edited  time.Time `db:"edited"`
-	Body    string    `db:"body"`
-	Description string `db:"description"`
+	ID          int       `db:"id"`
+	Title       string    `db:"title"`
+	Author      string    `db:"author"`
+	Created     time.Time `db:"created"`
+	Edited      time.Time `db:"edited"`
+	Body        string    `db:"body"`
+	Description string    `db:"description"`
+	Slug        string    `db:"slug"`
+	Status      Status    `db:"status"`
+	PublishAt   time.Time `db:"publish_at" firestore:"publish_at"`
+	Tags        []string  `db:"tags"`
+	// DeletedAt is when the post was moved to the trash, or nil for posts outside it
+	DeletedAt *time.Time `db:"deleted_at" firestore:"deleted_at"`
+	// Trusted posts are stored and rendered as uploaded, skipping HTML sanitization
+	Trusted bool `db:"trusted" firestore:"trusted"`
+	// Sanitized describes what was removed from the post's content by the HTML sanitizer on its last upload
+	Sanitized []string `db:"sanitized" firestore:"sanitized"`
+	// Filename is the name the content file was uploaded with; Body is the key it is stored under
+	Filename string `db:"filename" firestore:"filename"`
+}
+
+// Path returns the public URL of the post, falling back to the numeric route for posts without a slug
+func (p Post) Path() string {
+	if p.Slug == "" {
+		return "/blog/post/" + strconv.Itoa(p.ID)
+	}
+	return "/blog/" + p.Slug
 }
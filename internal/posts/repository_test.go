@@ -1,6 +1,7 @@
 package posts
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -15,6 +16,14 @@ func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 
+// postColumns lists the public.posts columns returned by selectPosts
+var postColumns = []string{"id", "title", "author", "created", "edited", "body", "description", "slug", "status", "publish_at", "deleted_at", "trusted", "sanitized", "filename", "tags"}
+
+// Helper function to build a mock row for a post in postColumns order
+func postValues(p Post) []any {
+	return []any{p.ID, p.Title, p.Author, p.Created, p.Edited, p.Body, p.Description, p.Slug, p.Status, p.PublishAt, p.DeletedAt, p.Trusted, p.Sanitized, p.Filename, p.Tags}
+}
+
 func TestConcreteRepository_GetPost(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -35,12 +44,12 @@ func TestConcreteRepository_GetPost(t *testing.T) {
 			Description: "Test description",
 		}
 
-		mock.ExpectQuery(`SELECT \* FROM public\.posts WHERE id = \$1`).
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE id = \$1`).
 			WithArgs(1).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "author", "created", "edited", "body", "description"}).
-				AddRow(expectedPost.ID, expectedPost.Title, expectedPost.Author, expectedPost.Created, expectedPost.Edited, expectedPost.Body, expectedPost.Description))
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(expectedPost)...))
 
-		post, err := repo.GetPost(1)
+		post, err := repo.GetPost(context.Background(), 1)
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -63,11 +72,11 @@ func TestConcreteRepository_GetPost(t *testing.T) {
 	})
 
 	t.Run("post not found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT \* FROM public\.posts WHERE id = \$1`).
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE id = \$1`).
 			WithArgs(999).
 			WillReturnError(pgx.ErrNoRows)
 
-		post, err := repo.GetPost(999)
+		post, err := repo.GetPost(context.Background(), 999)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -81,11 +90,11 @@ func TestConcreteRepository_GetPost(t *testing.T) {
 	})
 
 	t.Run("database error", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT \* FROM public\.posts WHERE id = \$1`).
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE id = \$1`).
 			WithArgs(1).
 			WillReturnError(pgx.ErrTxClosed)
 
-		post, err := repo.GetPost(1)
+		post, err := repo.GetPost(context.Background(), 1)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -103,6 +112,70 @@ func TestConcreteRepository_GetPost(t *testing.T) {
 	}
 }
 
+func TestConcreteRepository_GetPostBySlug(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful get post by slug", func(t *testing.T) {
+		expectedPost := Post{ID: 3, Title: "Hello World", Author: "Adam Shkolnik", Created: time.Now(), Edited: time.Now(), Body: "hello.html", Slug: "hello-world"}
+
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnRows(pgxmock.NewRows(postColumns).AddRow(postValues(expectedPost)...))
+
+		post, err := repo.GetPostBySlug(context.Background(), "hello-world")
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if post == nil || post.ID != 3 {
+			t.Errorf("expected post with ID 3, got %v", post)
+		}
+		if post != nil && post.Slug != "hello-world" {
+			t.Errorf("expected Slug hello-world, got %s", post.Slug)
+		}
+	})
+
+	t.Run("post not found", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("missing").
+			WillReturnRows(pgxmock.NewRows(postColumns))
+
+		post, err := repo.GetPostBySlug(context.Background(), "missing")
+
+		if !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("expected ErrPostNotFound, got %v", err)
+		}
+		if post != nil {
+			t.Error("expected nil post, got non-nil")
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, err := repo.GetPostBySlug(context.Background(), "hello-world")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+		if err != nil && !contains(err.Error(), "error getting post by slug") {
+			t.Errorf("expected error to contain 'error getting post by slug', got %v", err.Error())
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestConcreteRepository_GetPosts(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -135,12 +208,12 @@ func TestConcreteRepository_GetPosts(t *testing.T) {
 			},
 		}
 
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC`).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "author", "created", "edited", "body", "description"}).
-				AddRow(expectedPosts[0].ID, expectedPosts[0].Title, expectedPosts[0].Author, expectedPosts[0].Created, expectedPosts[0].Edited, expectedPosts[0].Body, expectedPosts[0].Description).
-				AddRow(expectedPosts[1].ID, expectedPosts[1].Title, expectedPosts[1].Author, expectedPosts[1].Created, expectedPosts[1].Edited, expectedPosts[1].Body, expectedPosts[1].Description))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE deleted_at IS NULL ORDER BY created DESC`).
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(expectedPosts[0])...).
+				AddRow(postValues(expectedPosts[1])...))
 
-		posts, err := repo.GetPosts()
+		posts, err := repo.GetPosts(context.Background())
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -157,10 +230,10 @@ func TestConcreteRepository_GetPosts(t *testing.T) {
 	})
 
 	t.Run("empty result", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC`).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "author", "created", "edited", "body", "description"}))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE deleted_at IS NULL ORDER BY created DESC`).
+			WillReturnRows(pgxmock.NewRows(postColumns))
 
-		posts, err := repo.GetPosts()
+		posts, err := repo.GetPosts(context.Background())
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -171,10 +244,10 @@ func TestConcreteRepository_GetPosts(t *testing.T) {
 	})
 
 	t.Run("database error", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC`).
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE deleted_at IS NULL ORDER BY created DESC`).
 			WillReturnError(pgx.ErrTxClosed)
 
-		posts, err := repo.GetPosts()
+		posts, err := repo.GetPosts(context.Background())
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -202,10 +275,10 @@ func TestConcreteRepository_GetTotalPostsCount(t *testing.T) {
 	repo := ConcreteRepository{Pool: mock}
 
 	t.Run("successful count", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts WHERE deleted_at IS NULL`).
 			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(10))
 
-		count, err := repo.GetTotalPostsCount()
+		count, err := repo.GetTotalPostsCount(context.Background())
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -219,7 +292,7 @@ func TestConcreteRepository_GetTotalPostsCount(t *testing.T) {
 		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
 			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
 
-		count, err := repo.GetTotalPostsCount()
+		count, err := repo.GetTotalPostsCount(context.Background())
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -233,7 +306,7 @@ func TestConcreteRepository_GetTotalPostsCount(t *testing.T) {
 		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
 			WillReturnError(pgx.ErrTxClosed)
 
-		count, err := repo.GetTotalPostsCount()
+		count, err := repo.GetTotalPostsCount(context.Background())
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -268,17 +341,17 @@ func TestConcreteRepository_GetPostsPaginated(t *testing.T) {
 		}
 
 		// Mock count query
-		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts WHERE status IN \('published', 'scheduled'\) AND publish_at <= NOW\(\) AND deleted_at IS NULL`).
 			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(12))
 
 		// Mock paginated query
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC LIMIT \$1 OFFSET \$2`).
-			WithArgs(PostsPerPage, 0).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "author", "created", "edited", "body", "description"}).
-				AddRow(expectedPosts[0].ID, expectedPosts[0].Title, expectedPosts[0].Author, expectedPosts[0].Created, expectedPosts[0].Edited, expectedPosts[0].Body, expectedPosts[0].Description).
-				AddRow(expectedPosts[1].ID, expectedPosts[1].Title, expectedPosts[1].Author, expectedPosts[1].Created, expectedPosts[1].Edited, expectedPosts[1].Body, expectedPosts[1].Description))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE status IN \('published', 'scheduled'\) AND publish_at <= NOW\(\) AND deleted_at IS NULL AND \(created, id\) <= \(\s+SELECT created, id FROM public\.posts WHERE .* ORDER BY created DESC, id DESC OFFSET \$1 LIMIT 1\s+\) ORDER BY created DESC, id DESC LIMIT \$2`).
+			WithArgs(0, PostsPerPage).
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(expectedPosts[0])...).
+				AddRow(postValues(expectedPosts[1])...))
 
-		posts, pagination, err := repo.GetPostsPaginated(1)
+		posts, pagination, err := repo.GetPostsPaginated(context.Background(), 1, "")
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -307,19 +380,87 @@ func TestConcreteRepository_GetPostsPaginated(t *testing.T) {
 		if pagination.PrevPage != 0 {
 			t.Errorf("expected PrevPage 0, got %d", pagination.PrevPage)
 		}
+		if pagination.NextCursor != PostCursor(expectedPosts[1], false).Token() {
+			t.Errorf("expected NextCursor after the last post, got %q", pagination.NextCursor)
+		}
+		if pagination.PrevCursor != "" {
+			t.Errorf("expected no PrevCursor on the first page, got %q", pagination.PrevCursor)
+		}
+	})
+
+	t.Run("next page by cursor", func(t *testing.T) {
+		created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		cursor := Cursor{Created: created, ID: 4}
+		expectedPosts := []Post{
+			{ID: 3, Title: "Post 3", Created: created.Add(-time.Hour)},
+			{ID: 2, Title: "Post 2", Created: created.Add(-2 * time.Hour)},
+		}
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(12))
+
+		mock.ExpectQuery(`AND deleted_at IS NULL AND \(created, id\) < \(\$1, \$2\) ORDER BY created DESC, id DESC LIMIT \$3`).
+			WithArgs(created, 4, PostsPerPage).
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(expectedPosts[0])...).
+				AddRow(postValues(expectedPosts[1])...))
+
+		posts, pagination, err := repo.GetPostsPaginated(context.Background(), 2, cursor.Token())
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(posts) != 2 || posts[0].ID != 3 {
+			t.Errorf("expected posts 3 and 2, got %+v", posts)
+		}
+		if pagination.PrevCursor != PostCursor(expectedPosts[0], true).Token() {
+			t.Errorf("expected PrevCursor before the first post, got %q", pagination.PrevCursor)
+		}
+	})
+
+	t.Run("previous page by cursor", func(t *testing.T) {
+		created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		cursor := Cursor{Created: created, ID: 3, Before: true}
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(12))
+
+		// Read oldest first, then returned newest first
+		mock.ExpectQuery(`AND deleted_at IS NULL AND \(created, id\) > \(\$1, \$2\) ORDER BY created ASC, id ASC LIMIT \$3`).
+			WithArgs(created, 3, PostsPerPage).
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(Post{ID: 4, Created: created.Add(time.Hour)})...).
+				AddRow(postValues(Post{ID: 5, Created: created.Add(2 * time.Hour)})...))
+
+		posts, _, err := repo.GetPostsPaginated(context.Background(), 1, cursor.Token())
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(posts) != 2 || posts[0].ID != 5 || posts[1].ID != 4 {
+			t.Errorf("expected posts 5 and 4, got %+v", posts)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, err := repo.GetPostsPaginated(context.Background(), 1, "not-a-cursor")
+
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
 	})
 
 	t.Run("successful pagination - middle page", func(t *testing.T) {
 		// Mock count query
-		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts WHERE status IN \('published', 'scheduled'\) AND publish_at <= NOW\(\) AND deleted_at IS NULL`).
 			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(12))
 
 		// Mock paginated query for page 2 (offset 5)
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC LIMIT \$1 OFFSET \$2`).
-			WithArgs(PostsPerPage, 5).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "author", "created", "edited", "body", "description"}))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE status IN \('published', 'scheduled'\) AND publish_at <= NOW\(\) AND deleted_at IS NULL AND \(created, id\) <= \(\s+SELECT created, id FROM public\.posts WHERE .* ORDER BY created DESC, id DESC OFFSET \$1 LIMIT 1\s+\) ORDER BY created DESC, id DESC LIMIT \$2`).
+			WithArgs(5, PostsPerPage).
+			WillReturnRows(pgxmock.NewRows(postColumns))
 
-		_, pagination, err := repo.GetPostsPaginated(2)
+		_, pagination, err := repo.GetPostsPaginated(context.Background(), 2, "")
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -348,7 +489,7 @@ func TestConcreteRepository_GetPostsPaginated(t *testing.T) {
 		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
 			WillReturnError(pgx.ErrTxClosed)
 
-		posts, pagination, err := repo.GetPostsPaginated(1)
+		posts, pagination, err := repo.GetPostsPaginated(context.Background(), 1, "")
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -368,11 +509,11 @@ func TestConcreteRepository_GetPostsPaginated(t *testing.T) {
 		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
 			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(10))
 
-		mock.ExpectQuery(`SELECT \* FROM public\.posts ORDER BY created DESC LIMIT \$1 OFFSET \$2`).
-			WithArgs(PostsPerPage, 0).
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE status IN \('published', 'scheduled'\) AND publish_at <= NOW\(\) AND deleted_at IS NULL AND \(created, id\) <= \(\s+SELECT created, id FROM public\.posts WHERE .* ORDER BY created DESC, id DESC OFFSET \$1 LIMIT 1\s+\) ORDER BY created DESC, id DESC LIMIT \$2`).
+			WithArgs(0, PostsPerPage).
 			WillReturnError(pgx.ErrTxClosed)
 
-		posts, pagination, err := repo.GetPostsPaginated(1)
+		posts, pagination, err := repo.GetPostsPaginated(context.Background(), 1, "")
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -393,6 +534,31 @@ func TestConcreteRepository_GetPostsPaginated(t *testing.T) {
 	}
 }
 
+func TestConcreteRepository_ReservePostID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	mock.ExpectQuery(`SELECT nextval\(pg_get_serial_sequence\('public\.posts', 'id'\)\)`).
+		WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(int64(7)))
+
+	id, err := repo.ReservePostID(context.Background())
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if id != 7 {
+		t.Errorf("expected id 7, got %d", id)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestConcreteRepository_CreatePost(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -402,24 +568,64 @@ func TestConcreteRepository_CreatePost(t *testing.T) {
 
 	repo := ConcreteRepository{Pool: mock}
 
+	publishAt := time.Now().Add(time.Hour)
+	newPost := Post{Title: "New Post", Description: "New description", Body: "new-post.html", Filename: "index.html", Author: "Adam Shkolnik", Slug: "new-post", Status: StatusDraft, PublishAt: publishAt, Tags: []string{"go", "web"}}
+
+	insertQuery := `INSERT INTO public\.posts \(id, title, description, body, author, slug, status, publish_at, created, trusted, sanitized, filename, edited\) VALUES \(COALESCE\(NULLIF\(\$12, 0\), nextval\(pg_get_serial_sequence\('public\.posts', 'id'\)\)\), \$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, COALESCE\(\$10::text\[\], '\{\}'\), \$11, NOW\(\)\) RETURNING id`
+
 	t.Run("successful create", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO public\.posts \(title, description, body, author, created, edited\) VALUES \(\$1, \$2, \$3, \$4, NOW\(\), NOW\(\)\)`).
-			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik").
-			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertQuery).
+			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik", "new-post", StatusDraft, publishAt, pgxmock.AnyArg(), false, []string(nil), "index.html", 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec(`INSERT INTO public\.post_tags`).
+			WithArgs(3, []string{"go", "web"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		id, err := repo.CreatePost(context.Background(), newPost)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if id != 3 {
+			t.Errorf("expected id 3, got %d", id)
+		}
+	})
+
+	t.Run("create with explicit created date and reserved id", func(t *testing.T) {
+		created := time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC)
+		imported := newPost
+		imported.ID = 4
+		imported.Created = created
 
-		err := repo.CreatePost("New Post", "New description", "new-post.html", "Adam Shkolnik")
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertQuery).
+			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik", "new-post", StatusDraft, publishAt, created, false, []string(nil), "index.html", 4).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectExec(`INSERT INTO public\.post_tags`).
+			WithArgs(4, []string{"go", "web"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		id, err := repo.CreatePost(context.Background(), imported)
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
+		if id != 4 {
+			t.Errorf("expected id 4, got %d", id)
+		}
 	})
 
 	t.Run("database error", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO public\.posts \(title, description, body, author, created, edited\) VALUES \(\$1, \$2, \$3, \$4, NOW\(\), NOW\(\)\)`).
-			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik").
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertQuery).
+			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik", "new-post", StatusDraft, publishAt, pgxmock.AnyArg(), false, []string(nil), "index.html", 0).
 			WillReturnError(pgx.ErrTxClosed)
+		mock.ExpectRollback()
 
-		err := repo.CreatePost("New Post", "New description", "new-post.html", "Adam Shkolnik")
+		_, err := repo.CreatePost(context.Background(), newPost)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -429,6 +635,66 @@ func TestConcreteRepository_CreatePost(t *testing.T) {
 		}
 	})
 
+	t.Run("tag error rolls back the post", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertQuery).
+			WithArgs("New Post", "New description", "new-post.html", "Adam Shkolnik", "new-post", StatusDraft, publishAt, pgxmock.AnyArg(), false, []string(nil), "index.html", 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(`INSERT INTO public\.post_tags`).
+			WithArgs(5, []string{"go", "web"}).
+			WillReturnError(pgx.ErrTxClosed)
+		mock.ExpectRollback()
+
+		_, err := repo.CreatePost(context.Background(), newPost)
+
+		if err == nil || !contains(err.Error(), "error setting post tags") {
+			t.Errorf("expected a tags error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_ImportPost(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	created := time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC)
+	edited := created.Add(time.Hour)
+	deleted := edited.Add(time.Hour)
+	post := Post{ID: 42, Title: "Imported", Body: "42/post-0123456789ab.md", Author: "Guest", Status: StatusPublished, PublishAt: created, Created: created, Edited: edited, DeletedAt: &deleted, Tags: []string{"go"}, Filename: "post.md"}
+
+	t.Run("upserts the post and moves the sequence past its id", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO public\.posts .* ON CONFLICT \(id\) DO UPDATE`).
+			WithArgs(42, "Imported", "", "42/post-0123456789ab.md", "Guest", "", StatusPublished, created, created, edited, &deleted, false, []string(nil), "post.md").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`INSERT INTO public\.post_tags`).
+			WithArgs(42, []string{"go"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`SELECT setval\(pg_get_serial_sequence\('public\.posts', 'id'\), GREATEST\(nextval`).
+			WithArgs(42).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectCommit()
+
+		if err := repo.ImportPost(context.Background(), post); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("post without an id", func(t *testing.T) {
+		if err := repo.ImportPost(context.Background(), Post{Title: "No ID"}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unmet expectations: %v", err)
 	}
@@ -443,12 +709,22 @@ func TestConcreteRepository_UpdatePost(t *testing.T) {
 
 	repo := ConcreteRepository{Pool: mock}
 
+	publishAt := time.Now()
+	updated := Post{ID: 1, Title: "Updated Title", Description: "Updated description", Body: "updated-post.html", Filename: "updated-post.html", Author: "Adam Shkolnik", Slug: "updated-title", Status: StatusPublished, PublishAt: publishAt, Trusted: true, Sanitized: []string{"<script> element"}, Tags: []string{"go"}}
+
+	updateQuery := `UPDATE public\.posts SET title = \$2, description = \$3, body = \$4, author = \$5, slug = \$6, status = \$7, publish_at = \$8, trusted = \$9, sanitized = COALESCE\(\$10::text\[\], '\{\}'\), filename = \$11, edited = NOW\(\) WHERE id = \$1`
+
 	t.Run("successful update", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE public\.posts SET title = \$2, description = \$3, body = \$4, edited = NOW\(\) WHERE id = \$1`).
-			WithArgs(1, "Updated Title", "Updated description", "updated-post.html").
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).
+			WithArgs(1, "Updated Title", "Updated description", "updated-post.html", "Adam Shkolnik", "updated-title", StatusPublished, publishAt, true, []string{"<script> element"}, "updated-post.html").
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO public\.post_tags`).
+			WithArgs(1, []string{"go"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
 
-		err := repo.UpdatePost(1, "Updated Title", "Updated description", "updated-post.html")
+		err := repo.UpdatePost(context.Background(), updated)
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -456,11 +732,16 @@ func TestConcreteRepository_UpdatePost(t *testing.T) {
 	})
 
 	t.Run("post not found", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE public\.posts SET title = \$2, description = \$3, body = \$4, edited = NOW\(\) WHERE id = \$1`).
-			WithArgs(999, "Updated Title", "Updated description", "updated-post.html").
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).
+			WithArgs(999, "Updated Title", "Updated description", "updated-post.html", "Adam Shkolnik", "updated-title", StatusPublished, publishAt, true, []string{"<script> element"}, "updated-post.html").
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		missing := updated
+		missing.ID = 999
 
-		err := repo.UpdatePost(999, "Updated Title", "Updated description", "updated-post.html")
+		err := repo.UpdatePost(context.Background(), missing)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -471,11 +752,13 @@ func TestConcreteRepository_UpdatePost(t *testing.T) {
 	})
 
 	t.Run("database error", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE public\.posts SET title = \$2, description = \$3, body = \$4, edited = NOW\(\) WHERE id = \$1`).
-			WithArgs(1, "Updated Title", "Updated description", "updated-post.html").
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).
+			WithArgs(1, "Updated Title", "Updated description", "updated-post.html", "Adam Shkolnik", "updated-title", StatusPublished, publishAt, true, []string{"<script> element"}, "updated-post.html").
 			WillReturnError(pgx.ErrTxClosed)
+		mock.ExpectRollback()
 
-		err := repo.UpdatePost(1, "Updated Title", "Updated description", "updated-post.html")
+		err := repo.UpdatePost(context.Background(), updated)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -490,6 +773,112 @@ func TestConcreteRepository_UpdatePost(t *testing.T) {
 	}
 }
 
+func TestConcreteRepository_UpdatePostStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	publishAt := time.Now().Add(24 * time.Hour)
+
+	t.Run("successful status update", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET status = \$2, publish_at = \$3 WHERE id = \$1`).
+			WithArgs(1, StatusScheduled, publishAt).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err := repo.UpdatePostStatus(context.Background(), 1, StatusScheduled, publishAt)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("post not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET status = \$2, publish_at = \$3 WHERE id = \$1`).
+			WithArgs(999, StatusArchived, publishAt).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err := repo.UpdatePostStatus(context.Background(), 999, StatusArchived, publishAt)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+		if err != nil && !contains(err.Error(), "post with id 999 not found") {
+			t.Errorf("expected error to contain 'post with id 999 not found', got %v", err.Error())
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET status = \$2, publish_at = \$3 WHERE id = \$1`).
+			WithArgs(1, StatusDraft, publishAt).
+			WillReturnError(pgx.ErrTxClosed)
+
+		err := repo.UpdatePostStatus(context.Background(), 1, StatusDraft, publishAt)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+		if err != nil && !contains(err.Error(), "error updating post status") {
+			t.Errorf("expected error to contain 'error updating post status', got %v", err.Error())
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_UpdatePostBody(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful body update", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET body = \$2, filename = \$3 WHERE id = \$1`).
+			WithArgs(1, "1/post.html", "index.html").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		if err := repo.UpdatePostBody(context.Background(), 1, "1/post.html", "index.html"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("post not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET body = \$2, filename = \$3 WHERE id = \$1`).
+			WithArgs(999, "999/post.html", "index.html").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err := repo.UpdatePostBody(context.Background(), 999, "999/post.html", "index.html")
+
+		if err == nil || !contains(err.Error(), "post with id 999 not found") {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET body = \$2, filename = \$3 WHERE id = \$1`).
+			WithArgs(1, "1/post.html", "index.html").
+			WillReturnError(pgx.ErrTxClosed)
+
+		err := repo.UpdatePostBody(context.Background(), 1, "1/post.html", "index.html")
+
+		if err == nil || !contains(err.Error(), "error updating post body") {
+			t.Errorf("expected update error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
 func TestConcreteRepository_DeletePost(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -499,12 +888,12 @@ func TestConcreteRepository_DeletePost(t *testing.T) {
 
 	repo := ConcreteRepository{Pool: mock}
 
-	t.Run("successful delete", func(t *testing.T) {
-		mock.ExpectExec(`DELETE FROM public\.posts WHERE id = \$1`).
+	t.Run("moves post to trash", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET deleted_at = NOW\(\) WHERE id = \$1 AND deleted_at IS NULL`).
 			WithArgs(1).
-			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		err := repo.DeletePost(1)
+		err := repo.DeletePost(context.Background(), 1)
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -512,11 +901,11 @@ func TestConcreteRepository_DeletePost(t *testing.T) {
 	})
 
 	t.Run("post not found", func(t *testing.T) {
-		mock.ExpectExec(`DELETE FROM public\.posts WHERE id = \$1`).
+		mock.ExpectExec(`UPDATE public\.posts SET deleted_at = NOW\(\)`).
 			WithArgs(999).
-			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
-		err := repo.DeletePost(999)
+		err := repo.DeletePost(context.Background(), 999)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -527,11 +916,11 @@ func TestConcreteRepository_DeletePost(t *testing.T) {
 	})
 
 	t.Run("database error", func(t *testing.T) {
-		mock.ExpectExec(`DELETE FROM public\.posts WHERE id = \$1`).
+		mock.ExpectExec(`UPDATE public\.posts SET deleted_at = NOW\(\)`).
 			WithArgs(1).
 			WillReturnError(pgx.ErrTxClosed)
 
-		err := repo.DeletePost(1)
+		err := repo.DeletePost(context.Background(), 1)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -545,3 +934,429 @@ func TestConcreteRepository_DeletePost(t *testing.T) {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
+
+func TestConcreteRepository_GetDeletedPosts(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+	deletedAt := time.Now()
+
+	t.Run("most recently deleted first", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`).
+			WillReturnRows(pgxmock.NewRows(postColumns).
+				AddRow(postValues(Post{ID: 2, Title: "Gone", DeletedAt: &deletedAt})...))
+
+		deleted, err := repo.GetDeletedPosts(context.Background())
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].DeletedAt == nil || !deleted[0].DeletedAt.Equal(deletedAt) {
+			t.Errorf("unexpected deleted posts %+v", deleted)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_RestorePost(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful restore", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET deleted_at = NULL WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WithArgs(1).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		if err := repo.RestorePost(context.Background(), 1); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("post not in trash", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE public\.posts SET deleted_at = NULL`).
+			WithArgs(2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err := repo.RestorePost(context.Background(), 2)
+
+		if err == nil || !contains(err.Error(), "post with id 2 not found in trash") {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_PurgePost(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful purge", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM public\.posts WHERE id = \$1 AND deleted_at IS NOT NULL`).
+			WithArgs(1).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		if err := repo.PurgePost(context.Background(), 1); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("post not in trash", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM public\.posts`).
+			WithArgs(2).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err := repo.PurgePost(context.Background(), 2)
+
+		if err == nil || !contains(err.Error(), "post with id 2 not found in trash") {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM public\.posts`).
+			WithArgs(1).
+			WillReturnError(pgx.ErrTxClosed)
+
+		err := repo.PurgePost(context.Background(), 1)
+
+		if err == nil || !contains(err.Error(), "error purging post") {
+			t.Errorf("expected purge error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_GetPostsByTagPaginated(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful pagination", func(t *testing.T) {
+		now := time.Now()
+		expectedPost := Post{ID: 2, Title: "Tagged", Author: "Adam Shkolnik", Created: now, Edited: now, Body: "tagged.html", Tags: []string{"go"}}
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts WHERE .* AND id IN \(SELECT pt\.post_id FROM public\.post_tags pt JOIN public\.tags t ON t\.id = pt\.tag_id WHERE t\.name = \$1\)`).
+			WithArgs("go").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE .* AND id IN .* ORDER BY created DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs("go", PostsPerPage, 0).
+			WillReturnRows(pgxmock.NewRows(postColumns).AddRow(postValues(expectedPost)...))
+
+		posts, pagination, err := repo.GetPostsByTagPaginated(context.Background(), "go", 1)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if len(posts) != 1 || posts[0].ID != 2 {
+			t.Errorf("expected post 2, got %v", posts)
+		}
+		if pagination.TotalPosts != 1 {
+			t.Errorf("expected 1 total post, got %d", pagination.TotalPosts)
+		}
+	})
+
+	t.Run("count error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts`).
+			WithArgs("go").
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, _, err := repo.GetPostsByTagPaginated(context.Background(), "go", 1)
+
+		if err == nil || !contains(err.Error(), "error getting tagged posts count") {
+			t.Errorf("expected tagged posts count error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_GetTagCounts(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful query", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT t\.name, COUNT\(\*\) AS count FROM public\.tags t .* GROUP BY t\.name ORDER BY count DESC, t\.name`).
+			WillReturnRows(pgxmock.NewRows([]string{"name", "count"}).AddRow("go", 3).AddRow("web", 1))
+
+		counts, err := repo.GetTagCounts(context.Background())
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		expected := []TagCount{{Name: "go", Count: 3}, {Name: "web", Count: 1}}
+		if len(counts) != len(expected) {
+			t.Fatalf("expected %d tags, got %d", len(expected), len(counts))
+		}
+		for i, count := range counts {
+			if count != expected[i] {
+				t.Errorf("expected %v, got %v", expected[i], count)
+			}
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT t\.name, COUNT\(\*\) AS count FROM public\.tags t`).
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, err := repo.GetTagCounts(context.Background())
+
+		if err == nil || !contains(err.Error(), "error getting tag counts") {
+			t.Errorf("expected tag counts error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_SearchPosts(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful search", func(t *testing.T) {
+		now := time.Now()
+		match := Post{ID: 3, Title: "Go Tips", Author: "Adam Shkolnik", Created: now, Edited: now, Body: "go-tips.html"}
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts JOIN public\.post_search s ON s\.post_id = posts\.id WHERE .* AND s\.document @@ websearch_to_tsquery\('english', \$1\)`).
+			WithArgs("go tips").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`ts_rank\(s\.document, q\) AS rank, ts_headline\('english', s\.body, q, \$4\) AS snippet .* ORDER BY rank DESC, created DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs("go tips", PostsPerPage, 0, headlineOptions).
+			WillReturnRows(pgxmock.NewRows(append(postColumns, "rank", "snippet")).
+				AddRow(append(postValues(match), 0.8, "some go tips")...))
+
+		results, pagination, err := repo.SearchPosts(context.Background(), "go tips", 1)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		if results[0].ID != 3 || results[0].Rank != 0.8 || results[0].Snippet != "some go tips" {
+			t.Errorf("unexpected result %+v", results[0])
+		}
+		if pagination.TotalPosts != 1 {
+			t.Errorf("expected 1 total post, got %d", pagination.TotalPosts)
+		}
+	})
+
+	t.Run("search error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM public\.posts JOIN public\.post_search`).
+			WithArgs("go").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`ts_headline`).
+			WithArgs("go", PostsPerPage, 0, headlineOptions).
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, _, err := repo.SearchPosts(context.Background(), "go", 1)
+
+		if err == nil || !contains(err.Error(), "error searching posts") {
+			t.Errorf("expected search error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_IndexPost(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+	post := Post{ID: 3, Title: "Go Tips", Description: "Tips for Go"}
+
+	t.Run("successful index", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO public\.post_search \(post_id, body, document\) .* ON CONFLICT \(post_id\) DO UPDATE`).
+			WithArgs(3, "Go Tips", "Tips for Go", "body text").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		if err := repo.IndexPost(context.Background(), post, "body text"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO public\.post_search`).
+			WithArgs(3, "Go Tips", "Tips for Go", "body text").
+			WillReturnError(pgx.ErrTxClosed)
+
+		err := repo.IndexPost(context.Background(), post, "body text")
+
+		if err == nil || !contains(err.Error(), "error indexing post") {
+			t.Errorf("expected indexing error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// revisionColumns lists the public.post_revisions columns
+var revisionColumns = []string{"post_id", "number", "title", "description", "body", "slug", "tags", "content", "note", "created"}
+
+func TestConcreteRepository_CreateRevision(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+	post := Post{ID: 3, Title: "Go Tips", Description: "Tips for Go", Body: "go.md", Slug: "go-tips"}
+	created := time.Now()
+
+	t.Run("successful create", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO public\.post_revisions .* SELECT \$1, COALESCE\(MAX\(number\), 0\) \+ 1, .* WHERE post_id = \$1 RETURNING number, created`).
+			WithArgs(3, "Go Tips", "Tips for Go", "go.md", "go-tips", []string{}, "3-1-go.md", "Created").
+			WillReturnRows(pgxmock.NewRows([]string{"number", "created"}).AddRow(2, created))
+
+		rev, err := repo.CreateRevision(context.Background(), NewRevision(post, "3-1-go.md", "Created"))
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rev.Number != 2 || !rev.Created.Equal(created) {
+			t.Errorf("expected revision 2 created at %v, got %d at %v", created, rev.Number, rev.Created)
+		}
+		if rev.PostID != 3 || rev.Content != "3-1-go.md" {
+			t.Errorf("unexpected revision %+v", rev)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO public\.post_revisions`).
+			WithArgs(3, "Go Tips", "Tips for Go", "go.md", "go-tips", []string{}, "", "").
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, err := repo.CreateRevision(context.Background(), NewRevision(post, "", ""))
+
+		if err == nil || !contains(err.Error(), "error creating revision") {
+			t.Errorf("expected create error, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_GetRevisions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+	now := time.Now()
+
+	t.Run("newest first", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM public\.post_revisions WHERE post_id = \$1 ORDER BY number DESC`).
+			WithArgs(3).
+			WillReturnRows(pgxmock.NewRows(revisionColumns).
+				AddRow(3, 2, "New", "", "go.md", "go", []string{"go"}, "3-2-go.md", "Updated details", now).
+				AddRow(3, 1, "Old", "", "go.md", "go", []string{}, "3-1-go.md", "Created", now))
+
+		revisions, err := repo.GetRevisions(context.Background(), 3)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(revisions) != 2 || revisions[0].Number != 2 || revisions[1].Title != "Old" {
+			t.Errorf("unexpected revisions %+v", revisions)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestConcreteRepository_GetRevision(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("successful get revision", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM public\.post_revisions WHERE post_id = \$1 AND number = \$2`).
+			WithArgs(3, 1).
+			WillReturnRows(pgxmock.NewRows(revisionColumns).
+				AddRow(3, 1, "Old", "Desc", "go.md", "go", []string{"go"}, "3-1-go.md", "Created", time.Now()))
+
+		rev, err := repo.GetRevision(context.Background(), 3, 1)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rev.Number != 1 || rev.Title != "Old" || rev.Content != "3-1-go.md" {
+			t.Errorf("unexpected revision %+v", rev)
+		}
+	})
+
+	t.Run("revision not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM public\.post_revisions WHERE post_id = \$1 AND number = \$2`).
+			WithArgs(3, 9).
+			WillReturnRows(pgxmock.NewRows(revisionColumns))
+
+		_, err := repo.GetRevision(context.Background(), 3, 9)
+
+		if !errors.Is(err, ErrRevisionNotFound) {
+			t.Errorf("expected ErrRevisionNotFound, got %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
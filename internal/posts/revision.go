@@ -0,0 +1,47 @@
+package posts
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRevisionNotFound is returned when a post has no revision with the requested number
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is a saved version of a post's editable fields. Content names the versioned copy of
+// the post's content file kept by the content service, so later uploads cannot overwrite it.
+type Revision struct {
+	PostID      int       `db:"post_id" firestore:"post_id"`
+	Number      int       `db:"number" firestore:"number"`
+	Title       string    `db:"title" firestore:"title"`
+	Description string    `db:"description" firestore:"description"`
+	Body        string    `db:"body" firestore:"body"`
+	Slug        string    `db:"slug" firestore:"slug"`
+	Tags        []string  `db:"tags" firestore:"tags"`
+	Content     string    `db:"content" firestore:"content"`
+	Note        string    `db:"note" firestore:"note"`
+	Created     time.Time `db:"created" firestore:"created"`
+}
+
+// NewRevision captures the editable fields of a post for a revision stored under the given content copy
+func NewRevision(post Post, content, note string) Revision {
+	return Revision{
+		PostID:      post.ID,
+		Title:       post.Title,
+		Description: post.Description,
+		Body:        post.Body,
+		Slug:        post.Slug,
+		Tags:        post.Tags,
+		Content:     content,
+		Note:        note,
+	}
+}
+
+// Apply restores the revision's fields onto a post, leaving status, author and dates unchanged
+func (rev Revision) Apply(post *Post) {
+	post.Title = rev.Title
+	post.Description = rev.Description
+	post.Body = rev.Body
+	post.Slug = rev.Slug
+	post.Tags = rev.Tags
+}
@@ -0,0 +1,23 @@
+package posts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRevision_Apply(t *testing.T) {
+	publishAt := time.Now()
+	post := Post{ID: 3, Title: "New", Description: "New desc", Body: "new.md", Slug: "new", Tags: []string{"new"},
+		Author: "Adam Shkolnik", Status: StatusPublished, PublishAt: publishAt}
+	rev := NewRevision(Post{ID: 3, Title: "Old", Description: "Old desc", Body: "old.md", Slug: "old", Tags: []string{"old"}}, "3-1-old.md", "Created")
+
+	rev.Apply(&post)
+
+	if post.Title != "Old" || post.Description != "Old desc" || post.Body != "old.md" || post.Slug != "old" || !reflect.DeepEqual(post.Tags, []string{"old"}) {
+		t.Errorf("revision fields not restored: %+v", post)
+	}
+	if post.ID != 3 || post.Author != "Adam Shkolnik" || post.Status != StatusPublished || !post.PublishAt.Equal(publishAt) {
+		t.Errorf("fields outside the revision changed: %+v", post)
+	}
+}
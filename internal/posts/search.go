@@ -0,0 +1,9 @@
+package posts
+
+// SearchResult is a post matching a search query, with its relevance and a snippet of the matching text.
+// The snippet marks matched terms with search.MarkStart and search.MarkEnd.
+type SearchResult struct {
+	Post
+	Rank    float64 `db:"rank"`
+	Snippet string  `db:"snippet"`
+}
@@ -0,0 +1,86 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrPostNotFound is returned by lookups that find no matching post
+var ErrPostNotFound = errors.New("post not found")
+
+// MaxSlugLength matches the width of the slug column
+const MaxSlugLength = 100
+
+// reservedSlugs would be shadowed by the fixed routes under /blog/
+var reservedSlugs = map[string]bool{
+	"post":   true,
+	"posts":  true,
+	"search": true,
+	"tags":   true,
+}
+
+// Slugify converts a title into a lowercase, hyphen-separated URL segment
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+
+	// Decompose accented characters so "é" becomes "e" plus a combining mark we can drop
+	for _, r := range norm.NFKD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+			hyphen = false
+		case b.Len() > 0 && !hyphen:
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > MaxSlugLength {
+		slug = strings.TrimSuffix(slug[:MaxSlugLength], "-")
+	}
+
+	return slug
+}
+
+// UniqueSlug returns base, or base with a numeric suffix, such that no post other than id uses it.
+// Pass id 0 for posts that have not been created yet.
+func UniqueSlug(ctx context.Context, repo Repository, base string, id int) (string, error) {
+	if base == "" {
+		base = "post"
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			suffix := fmt.Sprintf("-%d", n)
+			if len(base)+len(suffix) > MaxSlugLength {
+				base = strings.TrimSuffix(base[:MaxSlugLength-len(suffix)], "-")
+			}
+			candidate = base + suffix
+		}
+
+		if reservedSlugs[candidate] {
+			continue
+		}
+
+		existing, err := repo.GetPostBySlug(ctx, candidate)
+		if errors.Is(err, ErrPostNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("error checking slug %s: %w", candidate, err)
+		}
+		if existing.ID == id {
+			return candidate, nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package posts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{"simple title", "Hello World", "hello-world"},
+		{"punctuation collapsed", "Go 1.24: What's New?!", "go-1-24-what-s-new"},
+		{"accents stripped", "Café Crème", "cafe-creme"},
+		{"leading and trailing separators", "  --Spaces--  ", "spaces"},
+		{"non-latin dropped", "日本語", ""},
+		{"empty title", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.expected {
+				t.Errorf("expected Slugify(%q) to be %q, got %q", tt.title, tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("long title truncated", func(t *testing.T) {
+		slug := Slugify(strings.Repeat("word ", 50))
+
+		if len(slug) > MaxSlugLength {
+			t.Errorf("expected slug length at most %d, got %d", MaxSlugLength, len(slug))
+		}
+		if strings.HasSuffix(slug, "-") {
+			t.Errorf("expected slug without trailing hyphen, got %q", slug)
+		}
+	})
+}
+
+func TestUniqueSlug(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	repo := ConcreteRepository{Pool: mock}
+
+	t.Run("unused slug", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnRows(pgxmock.NewRows(postColumns))
+
+		slug, err := UniqueSlug(context.Background(), repo, "hello-world", 0)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if slug != "hello-world" {
+			t.Errorf("expected hello-world, got %s", slug)
+		}
+	})
+
+	t.Run("slug taken by another post", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnRows(pgxmock.NewRows(postColumns).AddRow(postValues(Post{ID: 1, Slug: "hello-world"})...))
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world-2").
+			WillReturnRows(pgxmock.NewRows(postColumns))
+
+		slug, err := UniqueSlug(context.Background(), repo, "hello-world", 0)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if slug != "hello-world-2" {
+			t.Errorf("expected hello-world-2, got %s", slug)
+		}
+	})
+
+	t.Run("slug already owned by the same post", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnRows(pgxmock.NewRows(postColumns).AddRow(postValues(Post{ID: 7, Slug: "hello-world"})...))
+
+		slug, err := UniqueSlug(context.Background(), repo, "hello-world", 7)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if slug != "hello-world" {
+			t.Errorf("expected hello-world, got %s", slug)
+		}
+	})
+
+	t.Run("reserved slug", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("posts-2").
+			WillReturnRows(pgxmock.NewRows(postColumns))
+
+		slug, err := UniqueSlug(context.Background(), repo, "posts", 0)
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if slug != "posts-2" {
+			t.Errorf("expected posts-2, got %s", slug)
+		}
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		mock.ExpectQuery(`AS tags FROM public\.posts WHERE slug = \$1`).
+			WithArgs("hello-world").
+			WillReturnError(pgx.ErrTxClosed)
+
+		_, err := UniqueSlug(context.Background(), repo, "hello-world", 0)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status is the lifecycle state of a post
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusScheduled Status = "scheduled"
+	StatusPublished Status = "published"
+	StatusArchived  Status = "archived"
+)
+
+// Statuses lists every lifecycle state in display order
+var Statuses = []Status{StatusDraft, StatusScheduled, StatusPublished, StatusArchived}
+
+// ParseStatus validates a status name received from a request or front matter
+func ParseStatus(s string) (Status, error) {
+	for _, status := range Statuses {
+		if Status(s) == status {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("invalid post status: %q", s)
+}
+
+// StatusAt returns the effective status at the given time: scheduled posts become published once PublishAt arrives.
+// Posts stored before statuses existed have no status and are treated as published.
+func (p Post) StatusAt(now time.Time) Status {
+	switch p.Status {
+	case "":
+		return StatusPublished
+	case StatusScheduled:
+		if !p.PublishAt.After(now) {
+			return StatusPublished
+		}
+	}
+	return p.Status
+}
+
+// IsVisibleAt reports whether the post is shown on the public site at the given time
+func (p Post) IsVisibleAt(now time.Time) bool {
+	return p.StatusAt(now) == StatusPublished && !p.PublishAt.After(now) && p.DeletedAt == nil
+}
+
+// ApplyLifecycleDefaults fills in the status and publish time of a post being saved:
+// a missing status means published, a missing publish time means now,
+// and a published post with a future publish time is scheduled instead.
+func (p *Post) ApplyLifecycleDefaults(now time.Time) {
+	if p.Status == "" {
+		p.Status = StatusPublished
+	}
+	if p.PublishAt.IsZero() {
+		p.PublishAt = now
+	}
+	if p.Status == StatusPublished && p.PublishAt.After(now) {
+		p.Status = StatusScheduled
+	}
+}
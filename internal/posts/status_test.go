@@ -0,0 +1,86 @@
+package posts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, status := range Statuses {
+		got, err := ParseStatus(string(status))
+		if err != nil {
+			t.Errorf("expected no error for %q, got %v", status, err)
+		}
+		if got != status {
+			t.Errorf("expected %q, got %q", status, got)
+		}
+	}
+
+	if _, err := ParseStatus("deleted"); err == nil {
+		t.Error("expected error for unknown status, got nil")
+	}
+}
+
+func TestPost_IsVisibleAt(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		post     Post
+		status   Status
+		expected bool
+	}{
+		{"published", Post{Status: StatusPublished, PublishAt: now.Add(-time.Hour)}, StatusPublished, true},
+		{"draft", Post{Status: StatusDraft, PublishAt: now.Add(-time.Hour)}, StatusDraft, false},
+		{"archived", Post{Status: StatusArchived, PublishAt: now.Add(-time.Hour)}, StatusArchived, false},
+		{"scheduled in future", Post{Status: StatusScheduled, PublishAt: now.Add(time.Hour)}, StatusScheduled, false},
+		{"scheduled time reached", Post{Status: StatusScheduled, PublishAt: now}, StatusPublished, true},
+		{"legacy post without status", Post{}, StatusPublished, true},
+		{"published in trash", Post{Status: StatusPublished, PublishAt: now.Add(-time.Hour), DeletedAt: &now}, StatusPublished, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.post.StatusAt(now); got != tt.status {
+				t.Errorf("expected StatusAt %q, got %q", tt.status, got)
+			}
+			if got := tt.post.IsVisibleAt(now); got != tt.expected {
+				t.Errorf("expected IsVisibleAt %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPost_ApplyLifecycleDefaults(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("new post defaults to published now", func(t *testing.T) {
+		post := Post{}
+		post.ApplyLifecycleDefaults(now)
+
+		if post.Status != StatusPublished {
+			t.Errorf("expected status published, got %q", post.Status)
+		}
+		if !post.PublishAt.Equal(now) {
+			t.Errorf("expected PublishAt %v, got %v", now, post.PublishAt)
+		}
+	})
+
+	t.Run("future publish time schedules the post", func(t *testing.T) {
+		post := Post{PublishAt: now.Add(time.Hour)}
+		post.ApplyLifecycleDefaults(now)
+
+		if post.Status != StatusScheduled {
+			t.Errorf("expected status scheduled, got %q", post.Status)
+		}
+	})
+
+	t.Run("draft keeps its status", func(t *testing.T) {
+		post := Post{Status: StatusDraft, PublishAt: now.Add(time.Hour)}
+		post.ApplyLifecycleDefaults(now)
+
+		if post.Status != StatusDraft {
+			t.Errorf("expected status draft, got %q", post.Status)
+		}
+	})
+}
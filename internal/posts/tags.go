@@ -0,0 +1,37 @@
+package posts
+
+import "strings"
+
+// MaxTagLength matches the width of the tag name column
+const MaxTagLength = 50
+
+// TagCount is a tag along with the number of public posts carrying it
+type TagCount struct {
+	Name  string `db:"name"`
+	Count int    `db:"count"`
+}
+
+// NormalizeTags turns free-form tag input into unique URL-safe names, preserving order
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		name := Slugify(tag)
+		if len(name) > MaxTagLength {
+			name = strings.TrimSuffix(name[:MaxTagLength], "-")
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+// ParseTags splits a comma-separated tag list as entered in the admin upload form
+func ParseTags(input string) []string {
+	return NormalizeTags(strings.Split(input, ","))
+}
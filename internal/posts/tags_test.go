@@ -0,0 +1,45 @@
+package posts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{"slugified", []string{"Go Lang", "Web Dev"}, []string{"go-lang", "web-dev"}},
+		{"duplicates removed", []string{"go", "Go", "GO "}, []string{"go"}},
+		{"empty tags dropped", []string{"", "  ", "go"}, []string{"go"}},
+		{"nil input", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTags(tt.tags); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("long tag truncated", func(t *testing.T) {
+		tags := NormalizeTags([]string{strings.Repeat("tag ", 30)})
+
+		if len(tags) != 1 || len(tags[0]) > MaxTagLength {
+			t.Errorf("expected one tag of at most %d characters, got %v", MaxTagLength, tags)
+		}
+	})
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags("go, web development,,Go")
+	expected := []string{"go", "web-development"}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
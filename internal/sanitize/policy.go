@@ -0,0 +1,103 @@
+package sanitize
+
+import "strings"
+
+// allElements is the key for attributes allowed on every element
+const allElements = "*"
+
+// Policy is an allowlist of elements and attributes. Event handler attributes and unsafe URLs
+// are removed even when allowed.
+type Policy struct {
+	elements   map[string]bool
+	attributes map[string]map[string]bool
+}
+
+// defaultElements are the elements posts can use: text structure, media and tables
+var defaultElements = []string{
+	"a", "abbr", "article", "aside", "audio", "b", "bdi", "bdo", "blockquote", "br", "caption", "cite", "code",
+	"col", "colgroup", "dd", "del", "details", "dfn", "div", "dl", "dt", "em", "figcaption", "figure", "footer",
+	"h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "i", "img", "ins", "kbd", "li", "mark", "ol", "p",
+	"picture", "pre", "q", "rp", "rt", "ruby", "s", "samp", "section", "small", "source", "span", "strong",
+	"sub", "summary", "sup", "table", "tbody", "td", "tfoot", "th", "thead", "time", "tr", "track", "u", "ul",
+	"var", "video", "wbr",
+}
+
+// defaultAttributes are the attributes allowed per element, with "*" applying to every element
+var defaultAttributes = map[string][]string{
+	allElements:  {"id", "class", "title", "lang", "dir"},
+	"a":          {"href", "name", "rel"},
+	"img":        {"src", "srcset", "sizes", "alt", "width", "height", "loading", "decoding"},
+	"source":     {"src", "srcset", "sizes", "type", "media"},
+	"video":      {"src", "poster", "controls", "width", "height", "loop", "muted", "playsinline", "preload"},
+	"audio":      {"src", "controls", "loop", "muted", "preload"},
+	"track":      {"src", "kind", "srclang", "label", "default"},
+	"td":         {"colspan", "rowspan", "align"},
+	"th":         {"colspan", "rowspan", "align", "scope"},
+	"col":        {"span"},
+	"colgroup":   {"span"},
+	"ol":         {"start", "reversed", "type"},
+	"li":         {"value"},
+	"blockquote": {"cite"},
+	"q":          {"cite"},
+	"del":        {"cite", "datetime"},
+	"ins":        {"cite", "datetime"},
+	"time":       {"datetime"},
+	"details":    {"open"},
+}
+
+// droppedContent are removed along with everything inside them, since their content is code or
+// markup that is only meaningful to the element itself
+var droppedContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true, "object": true, "embed": true,
+	"applet": true, "noscript": true, "noembed": true, "noframes": true, "template": true, "textarea": true,
+	"select": true, "title": true, "xmp": true, "svg": true, "math": true,
+}
+
+// DefaultPolicy returns the policy for post content: formatting, links, media and tables without
+// scripts, styles, embeds or forms
+func DefaultPolicy() *Policy {
+	p := &Policy{elements: make(map[string]bool), attributes: make(map[string]map[string]bool)}
+
+	p.AllowElements(defaultElements...)
+	for element, names := range defaultAttributes {
+		for _, name := range names {
+			p.allowAttribute(element, name)
+		}
+	}
+
+	return p
+}
+
+// AllowElements adds elements to the policy
+func (p *Policy) AllowElements(names ...string) {
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			p.elements[name] = true
+		}
+	}
+}
+
+// AllowAttributes adds attributes to the policy, either as "element:attribute" or as a bare
+// attribute name allowed on every element
+func (p *Policy) AllowAttributes(specs ...string) {
+	for _, spec := range specs {
+		element, name, found := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+		if !found {
+			element, name = allElements, element
+		}
+		if element != "" && name != "" {
+			p.allowAttribute(element, name)
+		}
+	}
+}
+
+func (p *Policy) allowAttribute(element, name string) {
+	if p.attributes[element] == nil {
+		p.attributes[element] = make(map[string]bool)
+	}
+	p.attributes[element][name] = true
+}
+
+func (p *Policy) allowsAttribute(element, name string) bool {
+	return p.attributes[element][name] || p.attributes[allElements][name]
+}
@@ -0,0 +1,207 @@
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// urlAttributes hold URLs, which must use a safe scheme
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "poster": true, "cite": true, "action": true, "formaction": true,
+	"background": true, "longdesc": true, "usemap": true, "ping": true,
+}
+
+// safeSchemes are the URL schemes links and media may use; relative URLs are always allowed
+var safeSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true}
+
+// dataImage matches inline raster images, the only data: URLs allowed, and only as image sources
+var dataImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp|avif);base64,`)
+
+// textAlign matches the only inline style allowed by default, which Markdown tables use for column alignment
+var textAlign = regexp.MustCompile(`^\s*text-align:\s*(left|right|center)\s*;?\s*$`)
+
+// Removal is one kind of markup the sanitizer removed and how many times it occurred
+type Removal struct {
+	Description string
+	Count       int
+}
+
+// String describes the removal for the admin report
+func (r Removal) String() string {
+	if r.Count > 1 {
+		return fmt.Sprintf("%s (%d times)", r.Description, r.Count)
+	}
+	return r.Description
+}
+
+// Report lists what the sanitizer removed, in the order first seen
+type Report []Removal
+
+// Strings describes every removal for the admin report
+func (r Report) Strings() []string {
+	descriptions := make([]string, 0, len(r))
+	for _, removal := range r {
+		descriptions = append(descriptions, removal.String())
+	}
+	return descriptions
+}
+
+func (r *Report) add(format string, args ...any) {
+	description := fmt.Sprintf(format, args...)
+	for i := range *r {
+		if (*r)[i].Description == description {
+			(*r)[i].Count++
+			return
+		}
+	}
+	*r = append(*r, Removal{Description: description, Count: 1})
+}
+
+// Sanitize removes everything the policy does not allow from an HTML fragment and reports what was removed.
+// Elements that are not allowed are unwrapped, keeping their text, except for scripts, styles, embeds and
+// similar elements, which are dropped along with their content.
+func (p *Policy) Sanitize(htmlContent string) (string, Report) {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(htmlContent))
+
+	var b strings.Builder
+	var report Report
+
+	// Name and nesting depth of a dropped element whose content is being skipped
+	skipping, depth := "", 0
+	// Raw text elements, such as an allowed <style>, keep their text unescaped
+	rawText := ""
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			return b.String(), report
+		}
+		token := tokenizer.Token()
+
+		if skipping != "" {
+			switch {
+			case tokenType == nethtml.StartTagToken && token.Data == skipping:
+				depth++
+			case tokenType == nethtml.EndTagToken && token.Data == skipping:
+				depth--
+				if depth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case nethtml.TextToken:
+			if rawText != "" {
+				b.WriteString(token.Data)
+			} else {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if !p.elements[token.Data] {
+				report.add("<%s> element", token.Data)
+				if droppedContent[token.Data] && tokenType == nethtml.StartTagToken {
+					skipping, depth = token.Data, 1
+				}
+				continue
+			}
+
+			token.Attr = p.sanitizeAttributes(token, &report)
+			b.WriteString(token.String())
+
+			if tokenType == nethtml.StartTagToken && droppedContent[token.Data] {
+				rawText = token.Data
+			}
+
+		case nethtml.EndTagToken:
+			if token.Data == rawText {
+				rawText = ""
+			}
+			if p.elements[token.Data] {
+				b.WriteString(token.String())
+			}
+
+		case nethtml.CommentToken:
+			report.add("HTML comment")
+
+		case nethtml.DoctypeToken:
+			// A document type is meaningless inside a post and not worth reporting
+		}
+	}
+}
+
+func (p *Policy) sanitizeAttributes(token nethtml.Token, report *Report) []nethtml.Attribute {
+	var kept []nethtml.Attribute
+
+	for _, attr := range token.Attr {
+		name := attr.Key
+		if attr.Namespace != "" {
+			name = attr.Namespace + ":" + attr.Key
+		}
+
+		switch {
+		case strings.HasPrefix(name, "on"):
+			report.add("%s event handler on <%s>", name, token.Data)
+
+		case name == "style" && !p.allowsAttribute(token.Data, name):
+			// Table cells keep their alignment, which is all Markdown tables use
+			if (token.Data == "td" || token.Data == "th") && textAlign.MatchString(attr.Val) {
+				kept = append(kept, attr)
+			} else {
+				report.add("style attribute on <%s>", token.Data)
+			}
+
+		case !p.allowsAttribute(token.Data, name):
+			report.add("%s attribute on <%s>", name, token.Data)
+
+		case urlAttributes[name] && !safeURL(attr.Val, token.Data == "img" || token.Data == "source"):
+			report.add("unsafe URL in %s on <%s>", name, token.Data)
+
+		case name == "srcset" && !safeSrcset(attr.Val):
+			report.add("unsafe URL in %s on <%s>", name, token.Data)
+
+		default:
+			kept = append(kept, attr)
+		}
+	}
+
+	return kept
+}
+
+// safeURL reports whether a URL is relative or uses a safe scheme. Browsers ignore whitespace and
+// control characters inside schemes, so "java\tscript:" is treated as "javascript:".
+func safeURL(value string, image bool) bool {
+	normalized := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+
+	colon := strings.IndexByte(normalized, ':')
+	if colon < 0 || strings.ContainsAny(normalized[:colon], "/?#") {
+		return true
+	}
+
+	scheme := strings.ToLower(normalized[:colon])
+	if safeSchemes[scheme] {
+		return true
+	}
+
+	return image && scheme == "data" && dataImage.MatchString(strings.ToLower(normalized))
+}
+
+func safeSrcset(value string) bool {
+	for _, candidate := range strings.Split(value, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 && !safeURL(fields[0], true) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package sanitize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		report   []string
+	}{
+		{
+			name:     "keeps allowed markup",
+			input:    `<h2 id="intro">Intro</h2><p>Go &amp; <a href="https://go.dev" rel="noopener">web</a></p><img src="/media/1/a.png" alt="A" width="10">`,
+			expected: `<h2 id="intro">Intro</h2><p>Go &amp; <a href="https://go.dev" rel="noopener">web</a></p><img src="/media/1/a.png" alt="A" width="10">`,
+		},
+		{
+			name:     "drops scripts with their content",
+			input:    `<p>a</p><script>alert("<p>x</p>")</script><SCRIPT src="x.js"></SCRIPT><p>b</p>`,
+			expected: `<p>a</p><p>b</p>`,
+			report:   []string{"<script> element (2 times)"},
+		},
+		{
+			name:     "unwraps unknown elements",
+			input:    `<form action="/x"><font color="red">text</font><input type="text"></form>`,
+			expected: `text`,
+			report:   []string{"<form> element", "<font> element", "<input> element"},
+		},
+		{
+			name:     "drops nested embeds",
+			input:    `<svg><svg><script>x</script></svg><text>hidden</text></svg><p>shown</p>`,
+			expected: `<p>shown</p>`,
+			report:   []string{"<svg> element"},
+		},
+		{
+			name:     "removes event handlers",
+			input:    `<img src="a.png" onerror="alert(1)"><p onclick="x()">p</p>`,
+			expected: `<img src="a.png"><p>p</p>`,
+			report:   []string{"onerror event handler on <img>", "onclick event handler on <p>"},
+		},
+		{
+			name:     "removes dangerous URLs",
+			input:    `<a href="javascript:alert(1)">a</a><a href="  JaVa&#x09;Script:alert(1)">b</a><img src="data:text/html;base64,PHNjcmlwdD4="><a href="/ok?x=1:2">c</a>`,
+			expected: `<a>a</a><a>b</a><img><a href="/ok?x=1:2">c</a>`,
+			report:   []string{"unsafe URL in href on <a> (2 times)", "unsafe URL in src on <img>"},
+		},
+		{
+			name:     "allows inline raster images",
+			input:    `<img src="data:image/png;base64,iVBORw0KGgo=">`,
+			expected: `<img src="data:image/png;base64,iVBORw0KGgo=">`,
+		},
+		{
+			name:     "checks every srcset candidate",
+			input:    `<img srcset="/a.png 1x, javascript:x 2x">`,
+			expected: `<img>`,
+			report:   []string{"unsafe URL in srcset on <img>"},
+		},
+		{
+			name:     "keeps only table alignment styles",
+			input:    `<table><tr><td style="text-align:right">1</td><td style="background:url(x)">2</td></tr></table><p style="color:red">p</p>`,
+			expected: `<table><tr><td style="text-align:right">1</td><td>2</td></tr></table><p>p</p>`,
+			report:   []string{"style attribute on <td>", "style attribute on <p>"},
+		},
+		{
+			name:     "removes comments and escapes text",
+			input:    `<!-- secret --><p>1 < 2 & "3"</p>`,
+			expected: `<p>1 &lt; 2 &amp; &#34;3&#34;</p>`,
+			report:   []string{"HTML comment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report := DefaultPolicy().Sanitize(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, got)
+			}
+			if strings := report.Strings(); !reflect.DeepEqual(strings, tt.report) && (len(strings) > 0 || len(tt.report) > 0) {
+				t.Errorf("expected report %v, got %v", tt.report, strings)
+			}
+		})
+	}
+}
+
+func TestPolicyAllow(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.AllowElements("iframe", " Font ")
+	policy.AllowAttributes("iframe:src", "data-id", "p:style")
+
+	input := `<iframe src="https://www.youtube.com/embed/x" data-id="1" onload="x()"></iframe><font>f</font><p style="color:red">p</p>`
+	expected := `<iframe src="https://www.youtube.com/embed/x" data-id="1"></iframe><font>f</font><p style="color:red">p</p>`
+
+	got, report := policy.Sanitize(input)
+	if got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	if len(report) != 1 || report[0].Description != "onload event handler on <iframe>" {
+		t.Errorf("unexpected report %v", report.Strings())
+	}
+}
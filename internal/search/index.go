@@ -0,0 +1,152 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Field weights follow PostgreSQL's default ts_rank weights for the A, B and C labels
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+	bodyWeight        = 0.2
+)
+
+// Fields is the searchable text of a post
+type Fields struct {
+	Title       string
+	Description string
+	Body        string
+}
+
+// Hit is a matching document and its relevance score
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+type document struct {
+	terms []string
+	body  string
+}
+
+// Index is an in-memory inverted index used where the database has no full-text search of its own.
+// It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int]float64
+	docs     map[int]document
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]float64),
+		docs:     make(map[int]document),
+	}
+}
+
+// Add indexes a document, replacing any previous version with the same id
+func (idx *Index) Add(id int, fields Fields) {
+	weights := make(map[string]float64)
+	for _, term := range Tokenize(fields.Title) {
+		weights[term] += titleWeight
+	}
+	for _, term := range Tokenize(fields.Description) {
+		weights[term] += descriptionWeight
+	}
+	for _, term := range Tokenize(fields.Body) {
+		weights[term] += bodyWeight
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int]float64)
+		}
+		idx.postings[term][id] = weight
+		terms = append(terms, term)
+	}
+	idx.docs[id] = document{terms: terms, body: fields.Body}
+}
+
+// Remove drops a document from the index
+func (idx *Index) Remove(id int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id int) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search returns documents containing every query term, best matches first.
+// Scores are weighted term frequencies scaled by inverse document frequency.
+func (idx *Index) Search(query string) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[int]float64)
+	for i, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			return nil
+		}
+
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+		next := make(map[int]float64)
+		for id, weight := range postings {
+			if _, ok := scores[id]; ok || i == 0 {
+				next[id] = scores[id] + weight*idf
+			}
+		}
+		scores = next
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	// Newer posts have higher ids and win ties
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	return hits
+}
+
+// Snippet returns the highlighted excerpt of a document's body for the query
+func (idx *Index) Snippet(id int, query string) string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return Snippet(idx.docs[id].body, query)
+}
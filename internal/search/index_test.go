@@ -0,0 +1,70 @@
+package search
+
+import "testing"
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, Fields{Title: "Getting started with Go", Body: "Install the toolchain"})
+	idx.Add(2, Fields{Title: "Web servers", Description: "Serving HTTP in Go", Body: "Go makes this easy"})
+	idx.Add(3, Fields{Title: "Cooking", Body: "Pasta recipes"})
+
+	t.Run("title matches rank first", func(t *testing.T) {
+		hits := idx.Search("go")
+
+		if len(hits) != 2 {
+			t.Fatalf("expected 2 hits, got %d", len(hits))
+		}
+		if hits[0].ID != 1 || hits[1].ID != 2 {
+			t.Errorf("expected hits [1 2], got %v", hits)
+		}
+	})
+
+	t.Run("every term must match", func(t *testing.T) {
+		hits := idx.Search("go http")
+
+		if len(hits) != 1 || hits[0].ID != 2 {
+			t.Errorf("expected only post 2, got %v", hits)
+		}
+	})
+
+	t.Run("unknown term", func(t *testing.T) {
+		if hits := idx.Search("rust"); len(hits) != 0 {
+			t.Errorf("expected no hits, got %v", hits)
+		}
+	})
+
+	t.Run("stop words only", func(t *testing.T) {
+		if hits := idx.Search("the"); hits != nil {
+			t.Errorf("expected no hits, got %v", hits)
+		}
+	})
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, Fields{Title: "Old title"})
+	idx.Add(1, Fields{Title: "New title"})
+
+	if hits := idx.Search("old"); len(hits) != 0 {
+		t.Errorf("expected replaced terms to be gone, got %v", hits)
+	}
+	if hits := idx.Search("new"); len(hits) != 1 {
+		t.Errorf("expected 1 hit for new title, got %v", hits)
+	}
+
+	idx.Remove(1)
+
+	if hits := idx.Search("new"); len(hits) != 0 {
+		t.Errorf("expected no hits after remove, got %v", hits)
+	}
+}
+
+func TestIndex_Snippet(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(1, Fields{Title: "Post", Body: "Some text about Go"})
+
+	expected := "Some text about " + MarkStart + "Go" + MarkEnd
+	if got := idx.Snippet(1, "go"); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// MarkStart and MarkEnd delimit matched terms in snippets until Highlight turns them into <mark> elements.
+// They are private-use characters so they never collide with post text.
+const (
+	MarkStart = "\ue000"
+	MarkEnd   = "\ue001"
+)
+
+// SnippetWords is the number of words shown around the first match in a snippet
+const SnippetWords = 30
+
+// stopWords are common English words left out of the index, as PostgreSQL's english configuration does
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true, "to": true, "was": true,
+	"will": true, "with": true,
+}
+
+// PlainText extracts the readable text of an HTML document, skipping scripts and styles
+func PlainText(htmlContent string) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(htmlContent))
+
+	var b strings.Builder
+	skip := 0
+	for {
+		switch tokenizer.Next() {
+		case nethtml.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case nethtml.StartTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "script" || string(name) == "style" {
+				skip++
+			}
+			b.WriteByte(' ')
+		case nethtml.EndTagToken:
+			if name, _ := tokenizer.TagName(); (string(name) == "script" || string(name) == "style") && skip > 0 {
+				skip--
+			}
+			b.WriteByte(' ')
+		case nethtml.SelfClosingTagToken:
+			b.WriteByte(' ')
+		case nethtml.TextToken:
+			if skip == 0 {
+				b.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// Tokenize splits text into lowercase index terms, dropping stop words
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// Snippet returns a window of text around the first word matching the query, with matches wrapped in MarkStart and MarkEnd
+func Snippet(text, query string) string {
+	terms := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+
+	words := strings.Fields(text)
+	matches := make([]bool, len(words))
+	first := -1
+	for i, word := range words {
+		for _, term := range Tokenize(word) {
+			if terms[term] {
+				matches[i] = true
+				break
+			}
+		}
+		if matches[i] && first == -1 {
+			first = i
+		}
+	}
+
+	// Start a few words before the first match so it reads in context
+	start := 0
+	if first > SnippetWords/4 {
+		start = first - SnippetWords/4
+	}
+	end := start + SnippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteByte(' ')
+		}
+		if matches[i] {
+			b.WriteString(MarkStart + words[i] + MarkEnd)
+		} else {
+			b.WriteString(words[i])
+		}
+	}
+	if end < len(words) {
+		b.WriteString(" …")
+	}
+
+	return b.String()
+}
+
+// Highlight escapes a snippet and turns its match markers into <mark> elements
+func Highlight(snippet string) template.HTML {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, MarkStart, "<mark>")
+	escaped = strings.ReplaceAll(escaped, MarkEnd, "</mark>")
+	return template.HTML(escaped)
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	html := `<h1>Hello</h1><p>Go &amp; <em>web</em></p><script>alert("x")</script><style>p{}</style><p>done</p>`
+
+	if got := PlainText(html); got != "Hello Go & web done" {
+		t.Errorf("expected %q, got %q", "Hello Go & web done", got)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Go-lang Tutorial, part 2!")
+	expected := []string{"go", "lang", "tutorial", "part", "2"}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	t.Run("marks matches", func(t *testing.T) {
+		got := Snippet("Learning Go is fun", "go")
+		expected := "Learning " + MarkStart + "Go" + MarkEnd + " is fun"
+
+		if got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("window around a late match", func(t *testing.T) {
+		text := strings.Repeat("filler ", 100) + "needle " + strings.Repeat("filler ", 100)
+		got := Snippet(text, "needle")
+
+		if !strings.HasPrefix(got, "… ") || !strings.HasSuffix(got, " …") {
+			t.Errorf("expected ellipses around snippet, got %q", got)
+		}
+		if !strings.Contains(got, MarkStart+"needle"+MarkEnd) {
+			t.Errorf("expected marked match in snippet, got %q", got)
+		}
+		if words := len(strings.Fields(got)); words > SnippetWords+2 {
+			t.Errorf("expected at most %d words, got %d", SnippetWords+2, words)
+		}
+	})
+
+	t.Run("no match shows the start", func(t *testing.T) {
+		if got := Snippet("one two three", "missing"); got != "one two three" {
+			t.Errorf("expected %q, got %q", "one two three", got)
+		}
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("<b>" + MarkStart + "Go" + MarkEnd + "</b>")
+	expected := "&lt;b&gt;<mark>Go</mark>&lt;/b&gt;"
+
+	if string(got) != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
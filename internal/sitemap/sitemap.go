@@ -0,0 +1,49 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// MaxURLs is the most URLs the sitemap protocol allows in a single file
+const MaxURLs = 50000
+
+// URL is a page listed in the sitemap. A zero LastMod is left out.
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Render encodes the URLs as a sitemap document
+func Render(urls []URL) ([]byte, error) {
+	if len(urls) > MaxURLs {
+		return nil, fmt.Errorf("sitemap has %d URLs, more than the limit of %d", len(urls), MaxURLs)
+	}
+
+	set := urlSet{URLs: make([]url, 0, len(urls))}
+	for _, u := range urls {
+		entry := url{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+
+	body, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sitemap: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,47 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	body, err := Render([]URL{
+		{Loc: "https://example.com/about"},
+		{Loc: "https://example.com/blog/hello?a=1&b=2", LastMod: time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+		URLs    []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("expected valid sitemap XML, got %v", err)
+	}
+
+	if len(doc.URLs) != 2 {
+		t.Fatalf("expected 2 URLs, got %d", len(doc.URLs))
+	}
+	if doc.URLs[0].LastMod != "" {
+		t.Errorf("expected no lastmod for static page, got %q", doc.URLs[0].LastMod)
+	}
+	if doc.URLs[1].Loc != "https://example.com/blog/hello?a=1&b=2" {
+		t.Errorf("expected escaped location to round-trip, got %q", doc.URLs[1].Loc)
+	}
+	if doc.URLs[1].LastMod != "2025-06-01T12:00:00Z" {
+		t.Errorf("unexpected lastmod %q", doc.URLs[1].LastMod)
+	}
+}
+
+func TestRender_TooManyURLs(t *testing.T) {
+	if _, err := Render(make([]URL, MaxURLs+1)); err == nil {
+		t.Error("expected error for oversized sitemap, got nil")
+	}
+}
@@ -0,0 +1,31 @@
+package timeout
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long a single read or write against a storage backend may take.
+// A zero duration leaves that kind of operation bounded only by the caller's context.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// ForRead returns a context for one read, cancelled with ctx or once the read timeout passes
+func (t Timeouts) ForRead(ctx context.Context) (context.Context, context.CancelFunc) {
+	return within(ctx, t.Read)
+}
+
+// ForWrite returns a context for one write, cancelled with ctx or once the write timeout passes
+func (t Timeouts) ForWrite(ctx context.Context) (context.Context, context.CancelFunc) {
+	return within(ctx, t.Write)
+}
+
+// Helper function to derive a context that expires after d, or just one that can be cancelled when d is not positive
+func within(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,35 @@
+package timeout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	timeouts := Timeouts{Read: time.Second}
+
+	ctx, cancel := timeouts.ForRead(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Second {
+		t.Errorf("expected a read deadline within a second, got %v (ok %v)", deadline, ok)
+	}
+
+	// A zero timeout leaves the write unbounded
+	ctx, cancel = timeouts.ForWrite(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no write deadline")
+	}
+
+	// An earlier deadline on the caller's context still applies
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelParent()
+	ctx, cancel = timeouts.ForRead(parent)
+	defer cancel()
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expected the caller's deadline to apply, got %v", ctx.Err())
+	}
+}
@@ -0,0 +1,325 @@
+// Package transfer copies posts and their content from one storage backend to another, keeping each post's id,
+// timestamps and author, and checks afterwards that the destination holds the same posts as the source.
+// Revision history and search entries are not copied; the destination rebuilds its search index at startup.
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"website/internal/content"
+	"website/internal/posts"
+)
+
+// Backend is a posts repository along with the content service holding its posts' content. The content service
+// should be a storage backend itself rather than a rendering decorator, so content is copied as it was uploaded.
+type Backend struct {
+	Posts   posts.Repository
+	Content content.ContentService
+}
+
+// Options change how Copy treats the destination
+type Options struct {
+	DryRun    bool // work out and report what would be copied without writing anything
+	Overwrite bool // replace destination posts that differ from the source instead of reporting them as conflicts
+}
+
+// Action is what Copy did, or would do in a dry run, with a post
+type Action string
+
+const (
+	ActionCreated   Action = "created"   // the destination had no post with the id
+	ActionUpdated   Action = "updated"   // the destination's post differed and was replaced
+	ActionUnchanged Action = "unchanged" // the destination already held an identical copy
+	ActionConflict  Action = "conflict"  // the destination's post differs and was left alone without Overwrite
+	ActionFailed    Action = "failed"    // reading or writing the post failed
+)
+
+// Result describes what happened to a single post
+type Result struct {
+	ID      int
+	Title   string
+	Action  Action
+	Assets  int      // assets copied along with the post
+	Details []string // how the destination differed, or why copying failed
+}
+
+// Report is the outcome of a copy or a verification
+type Report struct {
+	DryRun  bool
+	Results []Result
+}
+
+// Count returns how many posts ended with the given action
+func (r Report) Count(action Action) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// OK reports whether every post was copied or already matched
+func (r Report) OK() bool {
+	return r.Count(ActionConflict) == 0 && r.Count(ActionFailed) == 0
+}
+
+// Copy copies every post from one backend to another, trash included, along with its content and assets.
+// Posts the destination already holds an identical copy of are skipped, so re-running a copy only writes what
+// changed. A post's content and assets are written before the post itself, so a post is never left pointing
+// at content that was not copied.
+func Copy(ctx context.Context, from, to Backend, opts Options) (Report, error) {
+	source, existing, err := readBoth(ctx, from, to)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{DryRun: opts.DryRun}
+	for _, post := range source {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Results = append(report.Results, copyPost(ctx, from, to, post, existing[post.ID], opts))
+	}
+
+	return report, nil
+}
+
+// Verify compares every source post with the destination's copy, including content and assets, without writing
+// anything. Posts that match are reported as unchanged and posts that don't as conflicts listing the differences.
+func Verify(ctx context.Context, from, to Backend) (Report, error) {
+	source, existing, err := readBoth(ctx, from, to)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	for _, post := range source {
+		result := Result{ID: post.ID, Title: post.Title, Action: ActionUnchanged}
+
+		diff, err := compare(ctx, from, to, post, existing[post.ID])
+		switch {
+		case err != nil:
+			result.Action, result.Details = ActionFailed, []string{err.Error()}
+		case !diff.empty():
+			result.Action, result.Details = ActionConflict, diff.describe()
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// Helper function to copy a single post, deciding from what the destination already holds whether to write it
+func copyPost(ctx context.Context, from, to Backend, post posts.Post, existing *posts.Post, opts Options) Result {
+	result := Result{ID: post.ID, Title: post.Title}
+
+	diff, err := compare(ctx, from, to, post, existing)
+	if err != nil {
+		result.Action, result.Details = ActionFailed, []string{err.Error()}
+		return result
+	}
+
+	switch {
+	case existing == nil:
+		result.Action = ActionCreated
+	case diff.empty():
+		result.Action = ActionUnchanged
+		return result
+	case !opts.Overwrite:
+		result.Action, result.Details = ActionConflict, diff.describe()
+		return result
+	default:
+		result.Action, result.Details = ActionUpdated, diff.describe()
+	}
+
+	result.Assets = len(diff.assets)
+	if opts.DryRun {
+		return result
+	}
+
+	if err := write(ctx, to, post, diff); err != nil {
+		result.Action, result.Details = ActionFailed, []string{err.Error()}
+	}
+	return result
+}
+
+// Helper function to write the parts of a post the destination is missing, the post itself last
+func write(ctx context.Context, to Backend, post posts.Post, diff difference) error {
+	if diff.content {
+		if err := to.Content.SaveContent(ctx, post.Body, diff.document); err != nil {
+			return fmt.Errorf("failed to save content %s: %w", post.Body, err)
+		}
+	}
+
+	for _, asset := range diff.assets {
+		if err := to.Content.SaveAsset(ctx, asset.Name, asset.Data); err != nil {
+			return fmt.Errorf("failed to save asset %s: %w", asset.Name, err)
+		}
+	}
+
+	if len(diff.fields) > 0 {
+		if err := to.Posts.ImportPost(ctx, post); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// difference is how the destination's copy of a post falls short of the source
+type difference struct {
+	fields   []string        // post fields that differ, or "post" if the destination has no such post
+	content  bool            // the content is missing or differs
+	document string          // the source content, to write when it differs
+	assets   []content.Asset // source assets, with their data, that are missing or differ
+}
+
+// empty reports whether the destination already holds an identical copy
+func (d difference) empty() bool {
+	return len(d.fields) == 0 && !d.content && len(d.assets) == 0
+}
+
+// describe lists the differences for a report
+func (d difference) describe() []string {
+	var details []string
+	if len(d.fields) > 0 {
+		details = append(details, fmt.Sprintf("fields differ: %v", d.fields))
+	}
+	if d.content {
+		details = append(details, "content missing or different")
+	}
+	for _, asset := range d.assets {
+		details = append(details, "asset missing or different: "+asset.Name)
+	}
+	return details
+}
+
+// Helper function to compare a source post, its content and its assets with what the destination holds.
+// Errors reading the source are returned; anything the destination can't provide counts as a difference.
+func compare(ctx context.Context, from, to Backend, post posts.Post, existing *posts.Post) (difference, error) {
+	var diff difference
+
+	if existing == nil {
+		diff.fields = []string{"post"}
+	} else {
+		diff.fields = changedFields(post, *existing)
+	}
+
+	if post.Body != "" {
+		document, err := from.Content.GetContent(ctx, post.Body)
+		if err != nil {
+			return difference{}, fmt.Errorf("failed to read content %s: %w", post.Body, err)
+		}
+
+		copied, err := to.Content.GetContent(ctx, post.Body)
+		if err != nil || copied != document {
+			diff.content, diff.document = true, document
+		}
+	}
+
+	assets, err := from.Content.ListAssets(ctx, content.PostAssetPrefix(post.ID))
+	if err != nil {
+		return difference{}, fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	for _, listed := range assets {
+		asset, err := from.Content.GetAsset(ctx, listed.Name)
+		if err != nil {
+			return difference{}, fmt.Errorf("failed to read asset %s: %w", listed.Name, err)
+		}
+
+		copied, err := to.Content.GetAsset(ctx, listed.Name)
+		if err != nil || !bytes.Equal(copied.Data, asset.Data) {
+			diff.assets = append(diff.assets, asset)
+		}
+	}
+
+	return diff, nil
+}
+
+// Helper function to list the fields of a post that differ between two backends. Timestamps are compared to the
+// microsecond, the precision both backends store, and tags regardless of order.
+func changedFields(a, b posts.Post) []string {
+	sameTime := func(x, y time.Time) bool {
+		return x.Truncate(time.Microsecond).Equal(y.Truncate(time.Microsecond))
+	}
+	sameDeleted := (a.DeletedAt == nil) == (b.DeletedAt == nil) &&
+		(a.DeletedAt == nil || sameTime(*a.DeletedAt, *b.DeletedAt))
+
+	checks := []struct {
+		name string
+		same bool
+	}{
+		{"title", a.Title == b.Title},
+		{"author", a.Author == b.Author},
+		{"created", sameTime(a.Created, b.Created)},
+		{"edited", sameTime(a.Edited, b.Edited)},
+		{"body", a.Body == b.Body},
+		{"description", a.Description == b.Description},
+		{"slug", a.Slug == b.Slug},
+		{"status", a.Status == b.Status},
+		{"publish_at", sameTime(a.PublishAt, b.PublishAt)},
+		{"tags", slices.Equal(sorted(a.Tags), sorted(b.Tags))},
+		{"deleted_at", sameDeleted},
+		{"trusted", a.Trusted == b.Trusted},
+		{"sanitized", slices.Equal(a.Sanitized, b.Sanitized)},
+		{"filename", a.Filename == b.Filename},
+	}
+
+	var changed []string
+	for _, check := range checks {
+		if !check.same {
+			changed = append(changed, check.name)
+		}
+	}
+	return changed
+}
+
+// Helper function to read every post of a repository, trash included, sorted by id
+func allPosts(ctx context.Context, repo posts.Repository) ([]posts.Post, error) {
+	list, err := repo.GetPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := repo.GetDeletedPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list = append(list, deleted...)
+	slices.SortFunc(list, func(a, b posts.Post) int { return a.ID - b.ID })
+	return list, nil
+}
+
+// Helper function to read both sides of a copy, the destination's posts keyed by id
+func readBoth(ctx context.Context, from, to Backend) ([]posts.Post, map[int]*posts.Post, error) {
+	source, err := allPosts(ctx, from.Posts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read source posts: %w", err)
+	}
+
+	list, err := allPosts(ctx, to.Posts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read destination posts: %w", err)
+	}
+
+	existing := make(map[int]*posts.Post, len(list))
+	for _, post := range list {
+		existing[post.ID] = &post
+	}
+	return source, existing, nil
+}
+
+// Helper function to sort a copy of a list of strings
+func sorted(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return values
+}
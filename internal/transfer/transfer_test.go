@@ -0,0 +1,247 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"website/internal/content"
+	"website/internal/posts"
+)
+
+// memoryRepository is a Repository holding posts in a map, trash included
+type memoryRepository struct {
+	posts.Repository
+	posts   map[int]posts.Post
+	imports int
+}
+
+func (r *memoryRepository) GetPosts(ctx context.Context) ([]posts.Post, error) {
+	var list []posts.Post
+	for _, post := range r.posts {
+		if post.DeletedAt == nil {
+			list = append(list, post)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryRepository) GetDeletedPosts(ctx context.Context) ([]posts.Post, error) {
+	var list []posts.Post
+	for _, post := range r.posts {
+		if post.DeletedAt != nil {
+			list = append(list, post)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryRepository) ImportPost(ctx context.Context, post posts.Post) error {
+	r.imports++
+	r.posts[post.ID] = post
+	return nil
+}
+
+// memoryContent is a ContentService holding content and assets in maps
+type memoryContent struct {
+	content.ContentService
+	files  map[string]string
+	assets map[string][]byte
+	writes int
+}
+
+func (c *memoryContent) GetContent(ctx context.Context, filename string) (string, error) {
+	document, ok := c.files[filename]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return document, nil
+}
+
+func (c *memoryContent) SaveContent(ctx context.Context, filename, document string) error {
+	c.writes++
+	c.files[filename] = document
+	return nil
+}
+
+func (c *memoryContent) ListAssets(ctx context.Context, prefix string) ([]content.Asset, error) {
+	var assets []content.Asset
+	for name := range c.assets {
+		if strings.HasPrefix(name, prefix) {
+			assets = append(assets, content.Asset{Name: name})
+		}
+	}
+	return assets, nil
+}
+
+func (c *memoryContent) GetAsset(ctx context.Context, name string) (content.Asset, error) {
+	data, ok := c.assets[name]
+	if !ok {
+		return content.Asset{}, content.ErrAssetNotFound
+	}
+	return content.Asset{Name: name, Data: data}, nil
+}
+
+func (c *memoryContent) SaveAsset(ctx context.Context, name string, data []byte) error {
+	c.writes++
+	c.assets[name] = slices.Clone(data)
+	return nil
+}
+
+// Helper function to build an empty in-memory backend
+func newBackend() (Backend, *memoryRepository, *memoryContent) {
+	repo := &memoryRepository{posts: map[int]posts.Post{}}
+	store := &memoryContent{files: map[string]string{}, assets: map[string][]byte{}}
+	return Backend{Posts: repo, Content: store}, repo, store
+}
+
+// Helper function to build a source backend with a published post, with an image, and a post in the trash
+func newSource() (Backend, *memoryRepository, *memoryContent) {
+	backend, repo, store := newBackend()
+
+	created := time.Date(2023, time.March, 14, 9, 30, 0, 123456789, time.UTC)
+	deleted := created.Add(48 * time.Hour)
+	repo.posts[3] = posts.Post{ID: 3, Title: "Hello", Author: "Guest", Created: created, Edited: created.Add(time.Hour), Body: "3/post-aaaaaaaaaaaa.md", Tags: []string{"web", "go"}}
+	repo.posts[7] = posts.Post{ID: 7, Title: "Old", Author: "Adam Shkolnik", Created: created, Edited: created, Body: "7/post-bbbbbbbbbbbb.html", DeletedAt: &deleted}
+
+	store.files["3/post-aaaaaaaaaaaa.md"] = "# Hello"
+	store.files["7/post-bbbbbbbbbbbb.html"] = "<p>Old</p>"
+	store.assets["3/diagram.png"] = []byte("png")
+
+	return backend, repo, store
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("copies posts, content and assets", func(t *testing.T) {
+		from, source, _ := newSource()
+		to, dest, store := newBackend()
+
+		report, err := Copy(ctx, from, to, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Count(ActionCreated) != 2 || !report.OK() {
+			t.Fatalf("expected both posts created, got %+v", report.Results)
+		}
+		if report.Results[0].ID != 3 || report.Results[0].Assets != 1 {
+			t.Errorf("expected post 3 first with one asset, got %+v", report.Results[0])
+		}
+
+		for id, want := range source.posts {
+			got, ok := dest.posts[id]
+			if !ok || got.Author != want.Author || !got.Created.Equal(want.Created) || (got.DeletedAt == nil) != (want.DeletedAt == nil) {
+				t.Errorf("post %d not copied as it was: %+v", id, got)
+			}
+		}
+		if store.files["3/post-aaaaaaaaaaaa.md"] != "# Hello" || string(store.assets["3/diagram.png"]) != "png" {
+			t.Errorf("content not copied: %v %v", store.files, store.assets)
+		}
+
+		verified, err := Verify(ctx, from, to)
+		if err != nil || verified.Count(ActionUnchanged) != 2 {
+			t.Errorf("expected the copy to verify, got %+v (%v)", verified.Results, err)
+		}
+	})
+
+	t.Run("re-running writes nothing", func(t *testing.T) {
+		from, _, _ := newSource()
+		to, dest, store := newBackend()
+
+		if _, err := Copy(ctx, from, to, Options{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		imports, writes := dest.imports, store.writes
+
+		report, err := Copy(ctx, from, to, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Count(ActionUnchanged) != 2 {
+			t.Errorf("expected both posts unchanged, got %+v", report.Results)
+		}
+		if dest.imports != imports || store.writes != writes {
+			t.Error("expected a re-run not to write anything")
+		}
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		from, _, _ := newSource()
+		to, dest, store := newBackend()
+
+		report, err := Copy(ctx, from, to, Options{DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !report.DryRun || report.Count(ActionCreated) != 2 {
+			t.Errorf("expected both posts reported as created, got %+v", report.Results)
+		}
+		if len(dest.posts) != 0 || store.writes != 0 {
+			t.Error("expected a dry run not to write anything")
+		}
+	})
+
+	t.Run("differing posts are conflicts unless overwriting", func(t *testing.T) {
+		from, _, _ := newSource()
+		to, dest, _ := newBackend()
+
+		if _, err := Copy(ctx, from, to, Options{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		changed := dest.posts[3]
+		changed.Title = "Changed"
+		dest.posts[3] = changed
+
+		report, err := Copy(ctx, from, to, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Count(ActionConflict) != 1 || report.OK() || dest.posts[3].Title != "Changed" {
+			t.Errorf("expected a conflict leaving the post alone, got %+v", report.Results)
+		}
+
+		report, err = Copy(ctx, from, to, Options{Overwrite: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Count(ActionUpdated) != 1 || dest.posts[3].Title != "Hello" {
+			t.Errorf("expected the post to be replaced, got %+v", report.Results)
+		}
+	})
+
+	t.Run("missing source content fails the post", func(t *testing.T) {
+		from, _, source := newSource()
+		to, dest, _ := newBackend()
+		delete(source.files, "7/post-bbbbbbbbbbbb.html")
+
+		report, err := Copy(ctx, from, to, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Count(ActionFailed) != 1 || report.Count(ActionCreated) != 1 {
+			t.Errorf("expected one failure and one copy, got %+v", report.Results)
+		}
+		if _, ok := dest.posts[7]; ok {
+			t.Error("expected the post without content not to be copied")
+		}
+	})
+}
+
+func TestChangedFields(t *testing.T) {
+	created := time.Date(2023, time.March, 14, 9, 30, 0, 123456789, time.UTC)
+	a := posts.Post{ID: 1, Created: created, Tags: []string{"go", "web"}}
+	b := posts.Post{ID: 1, Created: created.Truncate(time.Microsecond).In(time.FixedZone("EST", -5*3600)), Tags: []string{"web", "go"}}
+
+	if changed := changedFields(a, b); len(changed) != 0 {
+		t.Errorf("expected stored precision, zone and tag order to be ignored, got %v", changed)
+	}
+
+	b.Author = "Someone"
+	b.Tags = []string{"go"}
+	if changed := changedFields(a, b); !slices.Equal(changed, []string{"author", "tags"}) {
+		t.Errorf("expected author and tags to differ, got %v", changed)
+	}
+}
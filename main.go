@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	"website/internal/config"
 	"website/internal/content"
 	"website/internal/database"
@@ -22,6 +23,17 @@ import (
 )
 
 func main() {
+	// Maintenance commands such as "migrate" run in place of the server
+	if len(os.Args) > 1 {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		err := runCommand(ctx, os.Args[1:])
+		stop()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	log.Println("starting server...")
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -50,55 +62,9 @@ func run(ctx context.Context, cancel context.CancelFunc) error {
 		return err
 	}
 
-	templates := parse.Parse()
-
-	// Initialize posts repository based on storage mode
-	var repo posts.Repository
-	if conf.StorageMode == "local" {
-		pool, err := database.Connect(ctx, conf.URL)
-		if err != nil {
-			return err
-		}
-		repo = posts.New(pool)
-		log.Println("Using PostgreSQL posts repository")
-	} else if conf.StorageMode == "gcs" {
-		firestoreClient, err := firestore.NewClient(ctx, conf.ProjectID)
-		if err != nil {
-			return err
-		}
-		repo = posts.NewFirestoreRepository(firestoreClient)
-		log.Println("Using Firestore posts repository")
-	} else {
-		// Default to local for unknown modes
-		pool, err := database.Connect(ctx, conf.URL)
-		if err != nil {
-			return err
-		}
-		repo = posts.New(pool)
-		log.Printf("Unknown storage mode '%s', falling back to PostgreSQL posts repository", conf.StorageMode)
-	}
-
-	// Initialize content service based on storage mode
-	var contentService content.ContentService
-	if conf.StorageMode == "local" {
-		contentService = content.NewFilesystemService(conf.PostsDirectory)
-		log.Printf("Using local filesystem content service with directory: %s", conf.PostsDirectory)
-	} else if conf.StorageMode == "gcs" {
-		if conf.GCSBucketName == "" {
-			log.Fatal("GCS_BUCKET_NAME is required when using GCS storage mode")
-		}
-
-		// Create GCS client
-		gcsClient, err := storage.NewClient(ctx)
-		if err != nil {
-			return err
-		}
-
-		contentService = content.NewGCSService(gcsClient, conf.GCSBucketName, conf.GCSPrefix)
-		log.Printf("Using GCS content service with bucket: %s, prefix: %s", conf.GCSBucketName, conf.GCSPrefix)
-	} else {
-		log.Printf("Unknown storage mode '%s', falling back to local filesystem", conf.StorageMode)
-		contentService = content.NewFilesystemService(conf.PostsDirectory)
+	env, err := newEnv(ctx, conf)
+	if err != nil {
+		return err
 	}
 
 	// Initialize Firebase Auth
@@ -116,22 +82,46 @@ func run(ctx context.Context, cancel context.CancelFunc) error {
 		return err
 	}
 
-	env := handlers.Env{
-		PostsRepository: repo,
-		ContentService:  contentService,
-		Templates:       templates,
-		EmailKey:        conf.EmailKey,
-		FirebaseAuth:    authClient,
-		Config:          conf,
-	}
+	env.EmailKey = conf.EmailKey
+	env.FirebaseAuth = authClient
+
+	// Build the search index in the background so startup is not held up by reading every post
+	go func() {
+		if err := env.ReindexPosts(ctx); err != nil {
+			log.Printf("failed to build search index: %v", err)
+		}
+	}()
+
+	// Purge posts that have outlived the trash retention period, then the content nothing refers to any more
+	go func() {
+		ticker := time.NewTicker(handlers.TrashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := env.PurgeExpiredPosts(ctx); err != nil {
+				log.Printf("failed to purge expired posts: %v", err)
+			}
+			if err := env.CollectContentGarbage(ctx); err != nil {
+				log.Printf("failed to collect content garbage: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
 
 	// Create separate routers
-	publicRouter := http.NewServeMux()
+	publicRouter := publicRoutes(env)
 	adminRouter := http.NewServeMux()
+	apiRouter := http.NewServeMux()
 
 	// Middleware stacks
 	publicMid := middleware.Stack(middleware.EnableCors, middleware.Logger)
 	adminMid := middleware.Stack(middleware.EnableCors, middleware.Logger, middleware.Auth(authClient))
+	apiMid := middleware.Stack(middleware.EnableCors, middleware.Logger)
 
 	// Create main router that delegates to sub-routers
 	mainRouter := http.NewServeMux()
@@ -154,19 +144,27 @@ func run(ctx context.Context, cancel context.CancelFunc) error {
 	adminRouter.HandleFunc("GET /posts", env.AdminListPostsHandler)
 	adminRouter.HandleFunc("GET /posts/{id}", env.AdminGetPostHandler)
 	adminRouter.HandleFunc("PUT /posts/{id}", env.AdminUpdatePostHandler)
+	adminRouter.HandleFunc("PUT /posts/{id}/status", env.AdminUpdatePostStatusHandler)
 	adminRouter.HandleFunc("DELETE /posts/{id}", env.AdminDeletePostHandler)
 	adminRouter.HandleFunc("POST /posts/upload", env.AdminUploadPostHandler)
+	adminRouter.HandleFunc("GET /posts/{id}/assets", env.AdminListAssetsHandler)
+	adminRouter.HandleFunc("POST /posts/{id}/assets", env.AdminUploadAssetsHandler)
+	adminRouter.HandleFunc("DELETE /posts/{id}/assets/{name}", env.AdminDeleteAssetHandler)
+	adminRouter.HandleFunc("GET /trash", env.AdminTrashHandler)
+	adminRouter.HandleFunc("POST /trash/{id}/restore", env.AdminRestorePostHandler)
+	adminRouter.HandleFunc("DELETE /trash/{id}", env.AdminPurgePostHandler)
+	adminRouter.HandleFunc("GET /posts/{id}/revisions", env.AdminListRevisionsHandler)
+	adminRouter.HandleFunc("GET /posts/{id}/revisions/diff", env.AdminDiffRevisionsHandler)
+	adminRouter.HandleFunc("POST /posts/{id}/revisions/{number}/restore", env.AdminRestoreRevisionHandler)
+	adminRouter.HandleFunc("GET /cache", env.AdminCacheStatsHandler)
 
-	// Public routes - use specific patterns to avoid conflicts
-	publicRouter.HandleFunc("GET /{$}", env.RootHandler)
-	publicRouter.HandleFunc("GET /about", env.AboutHandler)
-	publicRouter.HandleFunc("GET /blog/posts", env.PostsHandler)
-	publicRouter.HandleFunc("GET /blog/post/{id}", env.PostHandler)
-	publicRouter.HandleFunc("GET /contact", env.ContactHandler)
-	publicRouter.HandleFunc("POST /contact", env.MessageHandler)
+	// Public read-only API routes - relative paths since mounted under /api/v1/
+	apiRouter.HandleFunc("GET /posts", env.APIPostsHandler)
+	apiRouter.HandleFunc("GET /posts/{id}", env.APIPostHandler)
 
 	// Mount routers with their middleware - strip prefix for admin routes
 	mainRouter.Handle("/admin/", http.StripPrefix("/admin", adminMid(adminRouter)))
+	mainRouter.Handle("/api/v1/", http.StripPrefix("/api/v1", apiMid(apiRouter)))
 	mainRouter.Handle("/static/", http.StripPrefix("/static/", fs))
 	mainRouter.Handle("/", publicMid(publicRouter))
 
@@ -176,3 +174,111 @@ func run(ctx context.Context, cancel context.CancelFunc) error {
 
 	return nil
 }
+
+// newEnv sets up the posts repository, content service and templates for the configured storage mode.
+// Authentication and email are left to the server, since commands such as export render public pages only.
+func newEnv(ctx context.Context, conf config.Config) (handlers.Env, error) {
+	templates := parse.Parse()
+
+	// Initialize posts repository based on storage mode
+	var repo posts.Repository
+	if conf.StorageMode == "local" {
+		pool, err := database.Connect(ctx, conf.URL)
+		if err != nil {
+			return handlers.Env{}, err
+		}
+		if _, err := database.Migrate(ctx, pool); err != nil {
+			return handlers.Env{}, err
+		}
+		repo = posts.New(pool, conf.DatabaseTimeouts)
+		log.Println("Using PostgreSQL posts repository")
+	} else if conf.StorageMode == "gcs" {
+		firestoreClient, err := firestore.NewClient(ctx, conf.ProjectID)
+		if err != nil {
+			return handlers.Env{}, err
+		}
+		repo = posts.NewFirestoreRepository(firestoreClient, conf.DatabaseTimeouts)
+		log.Println("Using Firestore posts repository")
+	} else {
+		// Default to local for unknown modes
+		pool, err := database.Connect(ctx, conf.URL)
+		if err != nil {
+			return handlers.Env{}, err
+		}
+		if _, err := database.Migrate(ctx, pool); err != nil {
+			return handlers.Env{}, err
+		}
+		repo = posts.New(pool, conf.DatabaseTimeouts)
+		log.Printf("Unknown storage mode '%s', falling back to PostgreSQL posts repository", conf.StorageMode)
+	}
+
+	// Cache posts and post lists in memory so page views don't query the database every time
+	if conf.PostsCacheSize > 0 {
+		repo = posts.NewCachedRepository(repo, conf.PostsCacheSize, conf.PostsCacheTTL)
+		log.Printf("Caching up to %d posts and post lists for %s", conf.PostsCacheSize, conf.PostsCacheTTL)
+	}
+
+	// Initialize content service based on storage mode
+	var contentService content.ContentService
+	if conf.StorageMode == "local" {
+		contentService = content.NewFilesystemService(conf.PostsDirectory)
+		log.Printf("Using local filesystem content service with directory: %s", conf.PostsDirectory)
+	} else if conf.StorageMode == "gcs" {
+		if conf.GCSBucketName == "" {
+			log.Fatal("GCS_BUCKET_NAME is required when using GCS storage mode")
+		}
+
+		// Create GCS client
+		gcsClient, err := storage.NewClient(ctx)
+		if err != nil {
+			return handlers.Env{}, err
+		}
+
+		contentService = content.NewGCSService(gcsClient, conf.GCSBucketName, conf.GCSPrefix, conf.StorageTimeouts)
+		log.Printf("Using GCS content service with bucket: %s, prefix: %s", conf.GCSBucketName, conf.GCSPrefix)
+	} else {
+		log.Printf("Unknown storage mode '%s', falling back to local filesystem", conf.StorageMode)
+		contentService = content.NewFilesystemService(conf.PostsDirectory)
+	}
+
+	// Render Markdown posts to HTML on read
+	contentService = content.NewMarkdownService(contentService)
+
+	// Cache rendered content in memory so post views don't read storage every time
+	if conf.ContentCacheSize > 0 {
+		contentService = content.NewCacheService(contentService, conf.ContentCacheSize, conf.ContentCacheTTL)
+		log.Printf("Caching up to %d MB of post content for %s", conf.ContentCacheSize>>20, conf.ContentCacheTTL)
+	}
+
+	return handlers.Env{
+		PostsRepository: repo,
+		ContentService:  contentService,
+		Templates:       templates,
+		Config:          conf,
+	}, nil
+}
+
+// publicRoutes registers the public site's routes, which export also renders into static files
+func publicRoutes(env handlers.Env) *http.ServeMux {
+	publicRouter := http.NewServeMux()
+
+	// Use specific patterns to avoid conflicts
+	publicRouter.HandleFunc("GET /{$}", env.RootHandler)
+	publicRouter.HandleFunc("GET /about", env.AboutHandler)
+	publicRouter.HandleFunc("GET /blog/posts", env.PostsHandler)
+	publicRouter.HandleFunc("GET /blog/tags", env.TagsHandler)
+	publicRouter.HandleFunc("GET /blog/tags/{tag}", env.TagHandler)
+	publicRouter.HandleFunc("GET /blog/search", env.SearchHandler)
+	publicRouter.HandleFunc("GET /blog/feed.xml", env.RSSHandler)
+	publicRouter.HandleFunc("GET /blog/atom.xml", env.AtomHandler)
+	publicRouter.HandleFunc("GET /blog/feed.json", env.JSONFeedHandler)
+	publicRouter.HandleFunc("GET /blog/post/{id}", env.PostHandler)
+	publicRouter.HandleFunc("GET /blog/{slug}", env.PostBySlugHandler)
+	publicRouter.HandleFunc("GET /sitemap.xml", env.SitemapHandler)
+	publicRouter.HandleFunc("GET /robots.txt", env.RobotsHandler)
+	publicRouter.HandleFunc("GET /media/{path...}", env.MediaHandler)
+	publicRouter.HandleFunc("GET /contact", env.ContactHandler)
+	publicRouter.HandleFunc("POST /contact", env.MessageHandler)
+
+	return publicRouter
+}
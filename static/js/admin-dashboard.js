@@ -102,17 +102,37 @@ function setupEventListeners() {
   }
 
   // Delete confirmation
-  const deleteButtons = document.querySelectorAll('.btn-delete');
+  const deleteButtons = document.querySelectorAll('#posts .btn-delete');
   deleteButtons.forEach(button => {
     button.addEventListener('click', handleDeletePost);
   });
 
+  // Status transitions
+  const statusSelects = document.querySelectorAll('.status-select');
+  statusSelects.forEach(select => {
+    select.addEventListener('change', handleStatusChange);
+  });
+
   // Edit post functionality
-  const editButtons = document.querySelectorAll('.btn-edit');
+  const editButtons = document.querySelectorAll('#posts .btn-edit');
   editButtons.forEach(button => {
     button.addEventListener('click', handleEditPost);
   });
 
+  // Asset uploads for the post being edited
+  const assetUploadButton = document.getElementById('asset-upload');
+  if (assetUploadButton) {
+    assetUploadButton.addEventListener('click', handleAssetUpload);
+  }
+
+  // Trash actions
+  document.querySelectorAll('.btn-restore').forEach(button => {
+    button.addEventListener('click', handleRestorePost);
+  });
+  document.querySelectorAll('.btn-purge').forEach(button => {
+    button.addEventListener('click', handlePurgePost);
+  });
+
   // Logout functionality
   if (logoutButton) {
     logoutButton.addEventListener('click', handleLogout);
@@ -240,7 +260,7 @@ async function handleDeletePost(e) {
     return;
   }
   
-  if (confirm(`Are you sure you want to delete "${postTitle}"? This action cannot be undone.`)) {
+  if (confirm(`Move "${postTitle}" to the trash? It can be restored from the Trash tab until it is purged.`)) {
     try {
       const response = await fetch(`/admin/posts/${postId}`, {
         method: 'DELETE',
@@ -251,9 +271,9 @@ async function handleDeletePost(e) {
       });
       
       if (response.ok) {
-        // Remove the row from the table
-        e.target.closest('tr').remove();
-        alert('Post deleted successfully');
+        // Reload so the post shows up in the trash tab
+        alert('Post moved to the trash');
+        location.reload();
       } else {
         const error = await response.text();
         alert(`Failed to delete post: ${error}`);
@@ -265,6 +285,105 @@ async function handleDeletePost(e) {
   }
 }
 
+async function handleRestorePost(e) {
+  e.preventDefault();
+  const postId = e.target.getAttribute('data-id');
+  
+  try {
+    const response = await fetch(`/admin/trash/${postId}/restore`, {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      }
+    });
+    
+    if (response.ok) {
+      alert('Post restored');
+      location.reload();
+    } else {
+      const error = await response.text();
+      alert(`Failed to restore post: ${error}`);
+    }
+  } catch (error) {
+    console.error('Restore error:', error);
+    alert('Failed to restore post: Network error');
+  }
+}
+
+async function handlePurgePost(e) {
+  e.preventDefault();
+  const postId = e.target.getAttribute('data-id');
+  const postTitle = e.target.getAttribute('data-title') || 'this post';
+  
+  if (!confirm(`Permanently delete "${postTitle}" and its content? This action cannot be undone.`)) {
+    return;
+  }
+  
+  try {
+    const response = await fetch(`/admin/trash/${postId}`, {
+      method: 'DELETE',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      }
+    });
+    
+    if (response.ok) {
+      e.target.closest('tr').remove();
+      alert('Post permanently deleted');
+    } else {
+      const error = await response.text();
+      alert(`Failed to delete post: ${error}`);
+    }
+  } catch (error) {
+    console.error('Purge error:', error);
+    alert('Failed to delete post: Network error');
+  }
+}
+
+async function handleStatusChange(e) {
+  const select = e.target;
+  const postId = select.getAttribute('data-id');
+  const postTitle = select.getAttribute('data-title') || 'this post';
+  const previousStatus = select.getAttribute('data-current');
+  const status = select.value;
+
+  const body = { status };
+
+  if (status === 'scheduled') {
+    const publishAt = prompt(`When should "${postTitle}" be published? (YYYY-MM-DD HH:MM)`);
+    const date = publishAt ? new Date(publishAt.replace(' ', 'T')) : null;
+    if (!date || isNaN(date.getTime())) {
+      alert('Invalid publish time');
+      select.value = previousStatus;
+      return;
+    }
+    body.publishAt = date.toISOString();
+  }
+
+  try {
+    const response = await fetch(`/admin/posts/${postId}/status`, {
+      method: 'PUT',
+      headers: {
+        'Authorization': `Bearer ${authToken}`,
+        'Content-Type': 'application/json'
+      },
+      body: JSON.stringify(body)
+    });
+
+    if (response.ok) {
+      location.reload();
+    } else {
+      const error = await response.text();
+      alert(`Failed to update status: ${error}`);
+      select.value = previousStatus;
+    }
+  } catch (error) {
+    console.error('Status error:', error);
+    alert('Failed to update status: Network error');
+    select.value = previousStatus;
+  }
+}
+
 async function handleEditPost(e) {
   e.preventDefault();
   const postId = e.target.getAttribute('data-id');
@@ -301,12 +420,20 @@ async function handleEditPost(e) {
     // Populate the edit form with post data
     const editTitleInput = document.getElementById('edit-title');
     const editExcerptInput = document.getElementById('edit-excerpt');
+    const editSlugInput = document.getElementById('edit-slug');
+    const editTagsInput = document.getElementById('edit-tags');
     const editPostTitle = document.getElementById('edit-post-title');
     
     if (editTitleInput) editTitleInput.value = post.Title || '';
     if (editExcerptInput) editExcerptInput.value = post.Description || '';
+    if (editSlugInput) editSlugInput.value = post.Slug || '';
+    if (editTagsInput) editTagsInput.value = (post.Tags || []).join(', ');
     if (editPostTitle) editPostTitle.textContent = post.Title || 'Unknown';
     
+    const editTrustedInput = document.getElementById('edit-trusted');
+    if (editTrustedInput) editTrustedInput.checked = !!post.Trusted;
+    showSanitizeReport(post.Trusted ? [] : post.Sanitized || []);
+    
     // Clear any previously selected file
     if (editFileInput) {
       editFileInput.value = '';
@@ -316,22 +443,301 @@ async function handleEditPost(e) {
       editFileInfo.textContent = '';
     }
     
+    await loadAssets(postId);
+    await loadRevisions(postId);
+    
   } catch (error) {
     console.error('Edit error:', error);
     alert('Failed to load post for editing');
   }
 }
 
+async function loadAssets(postId) {
+  const assetList = document.getElementById('asset-list');
+  if (!assetList) return;
+  
+  assetList.innerHTML = '';
+  
+  const response = await fetch(`/admin/posts/${postId}/assets`, {
+    method: 'GET',
+    headers: {
+      'Authorization': `Bearer ${authToken}`
+    }
+  });
+  
+  if (!response.ok) {
+    throw new Error('Failed to fetch assets');
+  }
+  
+  const assets = await response.json();
+  if (assets.length === 0) {
+    assetList.innerHTML = '<li>No assets uploaded</li>';
+    return;
+  }
+  
+  assets.forEach(asset => {
+    const item = document.createElement('li');
+    
+    if (asset.content_type.startsWith('image/')) {
+      const preview = document.createElement('img');
+      preview.src = asset.url;
+      preview.alt = '';
+      item.appendChild(preview);
+    }
+    
+    const url = document.createElement('span');
+    url.className = 'asset-url';
+    url.textContent = asset.url;
+    item.appendChild(url);
+    
+    const copyButton = document.createElement('a');
+    copyButton.href = '#';
+    copyButton.className = 'btn-small btn-edit';
+    copyButton.textContent = 'Copy URL';
+    copyButton.addEventListener('click', async (e) => {
+      e.preventDefault();
+      try {
+        await navigator.clipboard.writeText(asset.url);
+        copyButton.textContent = 'Copied';
+      } catch (error) {
+        // Clipboard access can be denied; the URL is still shown for copying by hand
+        console.error('Copy error:', error);
+      }
+    });
+    item.appendChild(copyButton);
+    
+    const deleteButton = document.createElement('a');
+    deleteButton.href = '#';
+    deleteButton.className = 'btn-small btn-delete';
+    deleteButton.textContent = 'Delete';
+    deleteButton.addEventListener('click', (e) => {
+      e.preventDefault();
+      deleteAsset(postId, asset.name);
+    });
+    item.appendChild(deleteButton);
+    
+    assetList.appendChild(item);
+  });
+}
+
+async function handleAssetUpload() {
+  const assetInput = document.getElementById('asset-input');
+  if (!assetInput || !currentEditPostId) return;
+  
+  if (assetInput.files.length === 0) {
+    alert('Please choose files to upload');
+    return;
+  }
+  
+  const formData = new FormData();
+  for (const file of assetInput.files) {
+    formData.append('files', file);
+  }
+  
+  try {
+    const response = await fetch(`/admin/posts/${currentEditPostId}/assets`, {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      },
+      body: formData
+    });
+    
+    if (response.ok) {
+      assetInput.value = '';
+      await loadAssets(currentEditPostId);
+    } else {
+      const error = await response.text();
+      alert(`Failed to upload assets: ${error}`);
+    }
+  } catch (error) {
+    console.error('Asset upload error:', error);
+    alert('Failed to upload assets: Network error');
+  }
+}
+
+async function deleteAsset(postId, name) {
+  const filename = name.substring(name.indexOf('/') + 1);
+  if (!confirm(`Delete ${filename}? Posts using it will show a broken link.`)) {
+    return;
+  }
+  
+  try {
+    const response = await fetch(`/admin/posts/${postId}/assets/${encodeURIComponent(filename)}`, {
+      method: 'DELETE',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      }
+    });
+    
+    if (response.ok) {
+      await loadAssets(postId);
+    } else {
+      const error = await response.text();
+      alert(`Failed to delete asset: ${error}`);
+    }
+  } catch (error) {
+    console.error('Asset delete error:', error);
+    alert('Failed to delete asset: Network error');
+  }
+}
+
+async function loadRevisions(postId) {
+  const revisionList = document.getElementById('revision-list');
+  const revisionDiff = document.getElementById('revision-diff');
+  if (!revisionList) return;
+  
+  revisionList.innerHTML = '';
+  if (revisionDiff) {
+    revisionDiff.style.display = 'none';
+    revisionDiff.textContent = '';
+  }
+  
+  const response = await fetch(`/admin/posts/${postId}/revisions`, {
+    method: 'GET',
+    headers: {
+      'Authorization': `Bearer ${authToken}`
+    }
+  });
+  
+  if (!response.ok) {
+    throw new Error('Failed to fetch revisions');
+  }
+  
+  const revisions = await response.json();
+  if (revisions.length === 0) {
+    revisionList.innerHTML = '<li>No revisions yet</li>';
+    return;
+  }
+  
+  // Revisions come newest first, so every older one can be compared with the latest
+  const latest = revisions[0].Number;
+  revisions.forEach(revision => {
+    const item = document.createElement('li');
+    
+    const meta = document.createElement('span');
+    meta.className = 'revision-meta';
+    meta.textContent = `#${revision.Number} ${revision.Title} `;
+    const details = document.createElement('small');
+    details.textContent = `${revision.Note} - ${new Date(revision.Created).toLocaleString()}`;
+    meta.appendChild(details);
+    item.appendChild(meta);
+    
+    if (revision.Number !== latest) {
+      const diffButton = document.createElement('a');
+      diffButton.href = '#';
+      diffButton.className = 'btn-small btn-edit';
+      diffButton.textContent = 'Compare';
+      diffButton.addEventListener('click', (e) => {
+        e.preventDefault();
+        showRevisionDiff(postId, revision.Number, latest);
+      });
+      item.appendChild(diffButton);
+      
+      const restoreButton = document.createElement('a');
+      restoreButton.href = '#';
+      restoreButton.className = 'btn-small btn-delete';
+      restoreButton.textContent = 'Restore';
+      restoreButton.addEventListener('click', (e) => {
+        e.preventDefault();
+        restoreRevision(postId, revision.Number);
+      });
+      item.appendChild(restoreButton);
+    }
+    
+    revisionList.appendChild(item);
+  });
+}
+
+async function showRevisionDiff(postId, from, to) {
+  const revisionDiff = document.getElementById('revision-diff');
+  if (!revisionDiff) return;
+  
+  try {
+    const response = await fetch(`/admin/posts/${postId}/revisions/diff?from=${from}&to=${to}`, {
+      method: 'GET',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      }
+    });
+    
+    if (!response.ok) {
+      const error = await response.text();
+      alert(`Failed to compare revisions: ${error}`);
+      return;
+    }
+    
+    const result = await response.json();
+    const fields = result.fields.map(field => `${field.field}: "${field.from}" -> "${field.to}"`);
+    const text = [...fields, result.content].filter(part => part).join('\n');
+    
+    revisionDiff.textContent = text || `No changes between revision ${from} and ${to}`;
+    revisionDiff.style.display = 'block';
+  } catch (error) {
+    console.error('Diff error:', error);
+    alert('Failed to compare revisions: Network error');
+  }
+}
+
+async function restoreRevision(postId, number) {
+  if (!confirm(`Restore revision ${number}? The current version stays in the history.`)) {
+    return;
+  }
+  
+  try {
+    const response = await fetch(`/admin/posts/${postId}/revisions/${number}/restore`, {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${authToken}`
+      }
+    });
+    
+    if (response.ok) {
+      alert(`Revision ${number} restored`);
+      location.reload();
+    } else {
+      const error = await response.text();
+      alert(`Failed to restore revision: ${error}`);
+    }
+  } catch (error) {
+    console.error('Restore error:', error);
+    alert('Failed to restore revision: Network error');
+  }
+}
+
+function showSanitizeReport(removed) {
+  const section = document.getElementById('sanitize-section');
+  const list = document.getElementById('sanitize-report');
+  if (!section || !list) return;
+  
+  list.innerHTML = '';
+  removed.forEach(description => {
+    const item = document.createElement('li');
+    item.textContent = description;
+    list.appendChild(item);
+  });
+  
+  section.style.display = removed.length > 0 ? 'block' : 'none';
+}
+
 function cancelEdit() {
   // Reset edit form
   const editTitleInput = document.getElementById('edit-title');
   const editExcerptInput = document.getElementById('edit-excerpt');
+  const editSlugInput = document.getElementById('edit-slug');
+  const editTagsInput = document.getElementById('edit-tags');
   const editPostTitle = document.getElementById('edit-post-title');
   
   if (editTitleInput) editTitleInput.value = '';
   if (editExcerptInput) editExcerptInput.value = '';
+  if (editSlugInput) editSlugInput.value = '';
+  if (editTagsInput) editTagsInput.value = '';
   if (editPostTitle) editPostTitle.textContent = 'Loading...';
   
+  const editTrustedInput = document.getElementById('edit-trusted');
+  if (editTrustedInput) editTrustedInput.checked = false;
+  showSanitizeReport([]);
+  
   // Clear file input
   if (editFileInput) {
     editFileInput.value = '';
@@ -363,6 +769,12 @@ async function handleUploadFormSubmit(e) {
   // Create FormData from the form
   const formData = new FormData(e.target);
   
+  // Send the publish time with its timezone
+  const publishAt = formData.get('publishAt');
+  if (publishAt) {
+    formData.set('publishAt', new Date(publishAt).toISOString());
+  }
+  
   // Add auth token to form data
   if (authToken) {
     formData.append('authToken', authToken);
@@ -407,7 +819,10 @@ async function handleEditFormSubmit(e) {
   
   const titleInput = document.getElementById('edit-title');
   const excerptInput = document.getElementById('edit-excerpt');
+  const slugInput = document.getElementById('edit-slug');
+  const tagsInput = document.getElementById('edit-tags');
   const fileInput = document.getElementById('edit-file-input');
+  const trustedInput = document.getElementById('edit-trusted');
   
   const hasNewFile = fileInput && fileInput.files && fileInput.files.length > 0;
   
@@ -416,6 +831,9 @@ async function handleEditFormSubmit(e) {
     const formData = new FormData();
     formData.append('title', titleInput.value);
     formData.append('excerpt', excerptInput.value);
+    formData.append('slug', slugInput.value);
+    formData.append('tags', tagsInput.value);
+    formData.append('trusted', trustedInput && trustedInput.checked ? 'true' : 'false');
     formData.append('htmlFile', fileInput.files[0]);
     formData.append('editMode', 'true');
     formData.append('postId', currentEditPostId);
@@ -446,7 +864,10 @@ async function handleEditFormSubmit(e) {
     // Handle metadata-only update - use JSON
     const updatedData = {
       title: titleInput.value,
-      description: excerptInput.value
+      description: excerptInput.value,
+      slug: slugInput.value,
+      tags: tagsInput.value.split(',').map(tag => tag.trim()).filter(tag => tag),
+      trusted: !!(trustedInput && trustedInput.checked)
     };
     
     try {